@@ -0,0 +1,352 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BatchStatus Status of a recipient in a batch request.
+type BatchStatus struct {
+	To      *PhoneNumber
+	Error   error
+	Receipt *Receipt
+}
+
+// BatchResult Send results of a recipient in a batch send.
+type BatchResult struct {
+	To      *PhoneNumber
+	Status  string
+	Results []*Result
+	// Error Error that stopped the recipient before any gateway was tried, e.g. ErrRateLimited.
+	Error error
+}
+
+func (r *BatchResult) String() string {
+	if r.Error != nil {
+		return fmt.Sprintf("to: %s, status: %s, error: %v", r.To, r.Status, r.Error)
+	}
+
+	return fmt.Sprintf("to: %s, status: %s, results: %v", r.To, r.Status, r.Results)
+}
+
+// SendBatch Send a message to multiple recipients.
+func (g *Gsms) SendBatch(recipients []*PhoneNumber, message Message, gateways ...string) ([]*BatchResult, error) {
+	return g.SendBatchContext(context.Background(), recipients, message, gateways...)
+}
+
+// SendBatchContext Send a message to multiple recipients with context.
+// Gateways implementing BatchGateway send in chunks of their batch size, others send to each recipient.
+// Recipients that failed are retried with the next gateway unless the error is caused by the recipient,
+// the batch results are in the same order as recipients.
+// With a RoutingStrategy, recipients routed to the same gateways are sent together.
+// Suppressed and deferred recipients are not failures, their status is StatusSuppressed and StatusDeferred.
+// The send hooks are called for each recipient.
+func (g *Gsms) SendBatchContext(ctx context.Context, recipients []*PhoneNumber, message Message, gateways ...string) ([]*BatchResult, error) {
+	requests := make([]*SendRequest, len(recipients))
+	batch := make([]*BatchResult, len(recipients))
+	recipients = append([]*PhoneNumber(nil), recipients...)
+
+	for i, recipient := range recipients {
+		requests[i] = &SendRequest{To: recipient, Message: message, Gateways: gateways}
+
+		err := g.beforeSend(ctx, requests[i])
+
+		recipients[i] = requests[i].To
+		batch[i] = &BatchResult{To: recipients[i], Status: StatusFailure, Error: err}
+	}
+
+	requested := gateways
+
+	gateways, err := g.candidateGateways(message, gateways)
+	if err != nil {
+		for i, request := range requests {
+			g.afterSend(ctx, request, nil, err)
+			batch[i].Error = err
+		}
+
+		return nil, err
+	}
+
+	defer func() {
+		for i, request := range requests {
+			g.afterSend(ctx, request, batch[i].Results, batch[i].err())
+		}
+	}()
+
+	s := &sending{
+		message:  message,
+		strategy: g.strategyOf(message),
+	}
+
+	allowed := make([]int, 0, len(recipients))
+
+	var unsent []int
+
+	for i, recipient := range recipients {
+		if batch[i].Error == nil {
+			batch[i].Error = g.checkSuppression(recipient, message)
+		}
+
+		if batch[i].Error == nil {
+			batch[i].Error = g.checkSendWindow(ctx, recipient, message, requested)
+		}
+
+		if batch[i].Error == nil {
+			batch[i].Error = g.limitRecipient(recipient)
+		}
+
+		var deferred *ErrSendDeferred
+		var suppressed *ErrRecipientSuppressed
+
+		switch {
+		case errors.As(batch[i].Error, &deferred):
+			batch[i].Status = StatusDeferred
+		case errors.As(batch[i].Error, &suppressed):
+			batch[i].Status = StatusSuppressed
+		case batch[i].Error != nil:
+			unsent = append(unsent, i)
+		default:
+			allowed = append(allowed, i)
+		}
+	}
+
+	for _, route := range g.routeBatch(s, gateways, recipients, allowed) {
+		if err := ctx.Err(); err != nil {
+			return batch, err
+		}
+
+		unsent = append(unsent, g.sendBatchRoute(ctx, s, g.demoteUnhealthy(route.gateways), recipients, route.recipients, batch)...)
+	}
+
+	sort.Ints(unsent)
+
+	if err := ctx.Err(); err != nil && len(unsent) > 0 {
+		return batch, err
+	}
+
+	if len(unsent) > 0 {
+		failed := make([]*BatchResult, 0, len(unsent))
+		for _, i := range unsent {
+			failed = append(failed, batch[i])
+		}
+
+		return batch, NewErrBatchFailed(failed)
+	}
+
+	return batch, nil
+}
+
+// err Error of the recipient as returned by Send, nil if it was sent.
+func (r *BatchResult) err() error {
+	switch {
+	case r.Status == StatusSuccess:
+		return nil
+	case r.Error != nil:
+		return r.Error
+	}
+
+	return NewErrGatewayFailed(r.Results)
+}
+
+// batchRoute Recipients sent with the same gateways.
+type batchRoute struct {
+	gateways   []string
+	recipients []int
+}
+
+// routeBatch Group the pending recipients by their gateways, the strategy is applied once unless it is a RoutingStrategy.
+func (g *Gsms) routeBatch(s *sending, gateways []string, recipients []*PhoneNumber, pending []int) []*batchRoute {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if _, ok := s.strategy.(RoutingStrategy); !ok {
+		return []*batchRoute{{
+			gateways:   g.orderGateways(s, gateways),
+			recipients: pending,
+		}}
+	}
+
+	var routes []*batchRoute
+	index := map[string]*batchRoute{}
+
+	for _, i := range pending {
+		ordered := g.orderGateways(&sending{to: recipients[i], message: s.message, strategy: s.strategy}, gateways)
+		key := strings.Join(ordered, "\x00")
+
+		route, ok := index[key]
+		if !ok {
+			route = &batchRoute{gateways: ordered}
+			index[key] = route
+			routes = append(routes, route)
+		}
+
+		route.recipients = append(route.recipients, i)
+	}
+
+	return routes
+}
+
+// sendBatchRoute Send the message to the pending recipients, failing over the gateways in order.
+// It returns the recipients that were not sent.
+func (g *Gsms) sendBatchRoute(ctx context.Context, s *sending, gateways []string, recipients []*PhoneNumber, pending []int, batch []*BatchResult) []int {
+	var rejected []int
+
+	start := time.Now()
+
+	for k, gateway := range gateways {
+		if len(pending) == 0 || ctx.Err() != nil {
+			break
+		}
+
+		results := g.attemptBatch(ctx, s, gateway, recipients, pending)
+
+		var failed []int
+
+		for j, i := range pending {
+			batch[i].Results = append(batch[i].Results, results[j]...)
+			result := results[j][len(results[j])-1]
+
+			if result.Status == StatusSuccess {
+				batch[i].Status = StatusSuccess
+			} else if isRecipientError(result.Error) {
+				rejected = append(rejected, i)
+			} else {
+				failed = append(failed, i)
+
+				if k+1 < len(gateways) {
+					g.events.emitFailover(recipients[i], results[j], gateways[k+1])
+				}
+			}
+		}
+
+		pending = failed
+	}
+
+	unsent := append(pending, rejected...)
+
+	if ctx.Err() == nil {
+		for _, i := range unsent {
+			g.events.emitSendExhausted(recipients[i], batch[i].Results, time.Since(start), NewErrGatewayFailed(batch[i].Results))
+		}
+	}
+
+	return unsent
+}
+
+// attemptBatch Send the message to the pending recipients via the gateway.
+// The attempts of each recipient are in the same order as pending, batch requests are not retried.
+func (g *Gsms) attemptBatch(ctx context.Context, s *sending, gateway string, recipients []*PhoneNumber, pending []int) [][]*Result {
+	results := make([][]*Result, len(pending))
+
+	gw, err := g.Gateway(gateway)
+	if err != nil {
+		for j, i := range pending {
+			results[j] = []*Result{{Gateway: gateway, Status: StatusFailure, Error: err, Attempt: 1}}
+			g.events.emitAttemptEnd(recipients[i], results[j][0])
+		}
+		return results
+	}
+
+	batchGateway, ok := gw.(BatchGateway)
+	if !ok || batchGateway.BatchSize() <= 1 {
+		for j, i := range pending {
+			results[j] = g.attemptWithRetry(ctx, &sending{to: recipients[i], message: s.message, strategy: s.strategy}, gateway)
+		}
+		return results
+	}
+
+	template, err := s.message.GetTemplate(gw)
+	if err != nil {
+		for j, i := range pending {
+			results[j] = []*Result{{Gateway: gateway, Status: StatusFailure, Error: err, Attempt: 1}}
+			g.events.emitAttemptEnd(recipients[i], results[j][0])
+		}
+		return results
+	}
+
+	size := batchGateway.BatchSize()
+
+	for start := 0; start < len(pending); start += size {
+		end := start + size
+		if end > len(pending) {
+			end = len(pending)
+		}
+
+		to := make([]*PhoneNumber, 0, end-start)
+		for _, i := range pending[start:end] {
+			to = append(to, recipients[i])
+		}
+
+		g.config.Logger.Infof("[%s] start send [template: %s] batch message to %d recipients", gateway, template, len(to))
+
+		for _, phoneNumber := range to {
+			g.events.emitAttemptStart(phoneNumber, &Result{Gateway: gateway, Template: template, Attempt: 1})
+		}
+
+		begin := time.Now()
+		var statuses []*BatchStatus
+		err := g.limitGateway(gateway)
+		if err == nil {
+			g.probeHealth(gateway)
+			statuses, err = g.sendChunk(ctx, batchGateway, to, s.message)
+		}
+		latency := time.Since(begin)
+		if err == nil && len(statuses) != len(to) {
+			err = fmt.Errorf("batch statuses count %d mismatch recipients count %d", len(statuses), len(to))
+		}
+
+		if err != nil {
+			g.config.Logger.Warnf("[%s] send [template: %s] batch message failed: %+v", gateway, template, err)
+		}
+
+		g.observe(s, gateway, latency, normalizeError(gateway, err))
+
+		for k := range to {
+			result := &Result{
+				Gateway:  gateway,
+				Status:   StatusSuccess,
+				Template: template,
+				Error:    err,
+				Attempt:  1,
+				Latency:  latency,
+				Cost:     g.estimateCost(s, gw, to[k]),
+			}
+
+			if result.Error == nil {
+				result.Error = statuses[k].Error
+				result.Receipt = statuses[k].Receipt
+			}
+
+			result.Error = normalizeError(gateway, result.Error)
+
+			if result.Error != nil {
+				result.Status = StatusFailure
+			}
+
+			g.observeRoute(s, to[k], gateway, latency, result.Error)
+			g.events.emitAttemptEnd(to[k], result)
+
+			results[start+k] = []*Result{result}
+		}
+
+		g.config.Logger.Infof("[%s] end send [template: %s] batch message\n", gateway, template)
+	}
+
+	return results
+}
+
+// sendChunk Send a chunk via the batch middlewares within Config.Timeout.
+func (g *Gsms) sendChunk(ctx context.Context, gateway BatchGateway, to []*PhoneNumber, message Message) ([]*BatchStatus, error) {
+	if g.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+		defer cancel()
+	}
+
+	return g.chainBatch(sendBatch)(ctx, gateway, to, message, g.config)
+}
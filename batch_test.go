@@ -0,0 +1,183 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testBatchGateway struct {
+	size    int
+	chunks  [][]*PhoneNumber
+	failure map[int]bool
+}
+
+func (t *testBatchGateway) Name() string {
+	return "batch"
+}
+
+func (t *testBatchGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	return errors.New("unexpected single send")
+}
+
+func (t *testBatchGateway) BatchSize() int {
+	return t.size
+}
+
+func (t *testBatchGateway) SendBatch(ctx context.Context, to []*PhoneNumber, message Message, config *Config) ([]*BatchStatus, error) {
+	t.chunks = append(t.chunks, to)
+
+	statuses := make([]*BatchStatus, 0, len(to))
+	for _, phoneNumber := range to {
+		status := &BatchStatus{To: phoneNumber}
+		if t.failure[phoneNumber.Number()] {
+			status.Error = errors.New("send failed")
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+var _ BatchGateway = (*testBatchGateway)(nil)
+
+type recordGateway struct {
+	sent []*PhoneNumber
+}
+
+func (r *recordGateway) Name() string {
+	return "single"
+}
+
+func (r *recordGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	r.sent = append(r.sent, to)
+	return nil
+}
+
+func TestGsms_SendBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchGateway := &testBatchGateway{size: 2, failure: map[int]bool{18800000002: true}}
+	singleGateway := &recordGateway{}
+
+	g := New([]Gateway{batchGateway, singleGateway}, WithGateways([]string{"batch", "single"}))
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+		NewPhoneNumber(18800000003, "86"),
+	}
+
+	batch, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, batchGateway.chunks, 2)
+	assert.Len(t, batchGateway.chunks[0], 2)
+	assert.Len(t, batchGateway.chunks[1], 1)
+	assert.Equal(t, []*PhoneNumber{recipients[1]}, singleGateway.sent)
+
+	assert.Len(t, batch, 3)
+	for i, result := range batch {
+		assert.Same(t, recipients[i], result.To)
+		assert.Equal(t, StatusSuccess, result.Status)
+	}
+
+	assert.Len(t, batch[0].Results, 1)
+	assert.Len(t, batch[1].Results, 2)
+	assert.Equal(t, "batch", batch[1].Results[0].Gateway)
+	assert.Equal(t, StatusFailure, batch[1].Results[0].Status)
+	assert.Equal(t, "single", batch[1].Results[1].Gateway)
+	assert.Equal(t, StatusSuccess, batch[1].Results[1].Status)
+}
+
+func TestGsms_SendBatch_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchGateway := &testBatchGateway{size: 10, failure: map[int]bool{18800000002: true}}
+
+	g := New([]Gateway{batchGateway}, WithGateways([]string{"batch"}))
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+	}
+
+	batch, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+
+	var failed *ErrBatchFailed
+	if assert.ErrorAs(t, err, &failed) {
+		assert.Len(t, failed.Results, 1)
+		assert.Same(t, recipients[1], failed.Results[0].To)
+	}
+
+	assert.Equal(t, StatusSuccess, batch[0].Status)
+	assert.Equal(t, StatusFailure, batch[1].Status)
+}
+
+func TestGsms_SendBatch_RoutingStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchGateway := &testBatchGateway{size: 10}
+	singleGateway := &recordGateway{}
+
+	strategy := NewMockRoutingStrategy(ctrl)
+	strategy.EXPECT().Route(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(to *PhoneNumber, message Message, gateways []string) []string {
+			if to.InChineseMainland() {
+				return []string{"batch"}
+			}
+			return []string{"single"}
+		}).Times(3)
+
+	g := New([]Gateway{batchGateway, singleGateway}, WithGateways([]string{"batch", "single"}), WithStrategy(strategy))
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(61234567, "852"),
+		NewPhoneNumber(18800000003, "86"),
+	}
+
+	batch, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, batchGateway.chunks, 1) {
+		assert.Equal(t, []*PhoneNumber{recipients[0], recipients[2]}, batchGateway.chunks[0])
+	}
+	assert.Equal(t, []*PhoneNumber{recipients[1]}, singleGateway.sent)
+
+	for i, result := range batch {
+		assert.Same(t, recipients[i], result.To)
+		assert.Equal(t, StatusSuccess, result.Status)
+	}
+}
+
+func TestGsms_SendBatch_RoutingFeedbackStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchGateway := &testBatchGateway{size: 10, failure: map[int]bool{18800000002: true}}
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+	}
+
+	strategy := NewMockRoutingFeedbackStrategy(ctrl)
+	strategy.EXPECT().Route(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"batch"}).Times(2)
+	strategy.EXPECT().ObserveRoute(recipients[0], "batch", gomock.Any(), nil)
+	strategy.EXPECT().ObserveRoute(recipients[1], "batch", gomock.Any(), gomock.Not(nil))
+
+	g := New([]Gateway{batchGateway}, WithGateways([]string{"batch"}), WithStrategy(strategy))
+
+	_, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+	assert.Error(t, err)
+}
@@ -1,8 +1,12 @@
 package gsms
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"time"
 )
 
 var (
@@ -11,6 +15,9 @@ var (
 	ErrMessageTypeError     = errors.New("message type error")
 	ErrInvalidPhoneNumber   = errors.New("invalid phone number")
 	ErrRequestDataTypeError = errors.New("request data type error")
+	ErrScheduleNotFound     = errors.New("scheduled send not found")
+	ErrScheduleUnsupported  = errors.New("gateway does not support scheduling")
+	ErrSchedulerClosed      = errors.New("scheduler closed")
 )
 
 type ErrGatewaysFailed struct {
@@ -25,6 +32,92 @@ func (e *ErrGatewaysFailed) Error() string {
 	return fmt.Sprintf("all gateways failed to send message: %v", e.Results)
 }
 
+// As Match *ErrRateLimited if every gateway was skipped for a local rate limit, the shortest wait is used.
+func (e *ErrGatewaysFailed) As(target interface{}) bool {
+	rateLimited, ok := target.(**ErrRateLimited)
+	if !ok || len(e.Results) == 0 {
+		return false
+	}
+
+	var shortest *ErrRateLimited
+	for _, result := range e.Results {
+		var limited *ErrRateLimited
+		if !errors.As(result.Error, &limited) {
+			return false
+		}
+
+		if shortest == nil || limited.RetryAfter < shortest.RetryAfter {
+			shortest = limited
+		}
+	}
+
+	*rateLimited = shortest
+
+	return true
+}
+
+// ErrOutsideSendWindow The local time of the recipient is outside the send window of the message category.
+type ErrOutsideSendWindow struct {
+	To       *PhoneNumber
+	Category string
+	// NextWindow Start of the next send window.
+	NextWindow time.Time
+}
+
+func NewErrOutsideSendWindow(to *PhoneNumber, category string, nextWindow time.Time) *ErrOutsideSendWindow {
+	return &ErrOutsideSendWindow{To: to, Category: category, NextWindow: nextWindow}
+}
+
+func (e *ErrOutsideSendWindow) Error() string {
+	return fmt.Sprintf("[%s] outside send window of category %q, next window at %s", e.To, e.Category, e.NextWindow.Format(time.RFC3339))
+}
+
+// ErrSendDeferred The message is outside the send window and scheduled at the start of the next one, it is not a failure.
+type ErrSendDeferred struct {
+	To       *PhoneNumber
+	Category string
+	Handle   *ScheduleHandle
+}
+
+func NewErrSendDeferred(to *PhoneNumber, category string, handle *ScheduleHandle) *ErrSendDeferred {
+	return &ErrSendDeferred{To: to, Category: category, Handle: handle}
+}
+
+func (e *ErrSendDeferred) Error() string {
+	return fmt.Sprintf("[%s] outside send window of category %q, deferred to %s", e.To, e.Category, e.Handle.At.Format(time.RFC3339))
+}
+
+// ErrRecipientSuppressed The recipient is on the suppression list for the message category.
+type ErrRecipientSuppressed struct {
+	To          *PhoneNumber
+	Category    string
+	Suppression *Suppression
+}
+
+func NewErrRecipientSuppressed(to *PhoneNumber, category string, suppression *Suppression) *ErrRecipientSuppressed {
+	return &ErrRecipientSuppressed{To: to, Category: category, Suppression: suppression}
+}
+
+func (e *ErrRecipientSuppressed) Error() string {
+	if e.Suppression != nil && e.Suppression.Reason != "" {
+		return fmt.Sprintf("[%s] recipient suppressed for category %q: %s", e.To, e.Category, e.Suppression.Reason)
+	}
+
+	return fmt.Sprintf("[%s] recipient suppressed for category %q", e.To, e.Category)
+}
+
+type ErrBatchFailed struct {
+	Results []*BatchResult
+}
+
+func NewErrBatchFailed(results []*BatchResult) *ErrBatchFailed {
+	return &ErrBatchFailed{Results: results}
+}
+
+func (e *ErrBatchFailed) Error() string {
+	return fmt.Sprintf("all gateways failed to send message to %d recipients: %v", len(e.Results), e.Results)
+}
+
 type ErrRequestFailed struct {
 	StatusCode int
 	Body       string
@@ -34,3 +127,199 @@ type ErrRequestFailed struct {
 func (e *ErrRequestFailed) Error() string {
 	return e.Message
 }
+
+// ErrRateLimited The send is rejected by the local rate limits before any request is made.
+type ErrRateLimited struct {
+	// Gateway Rate limited gateway, empty if the recipient is rate limited.
+	Gateway string
+	// To Rate limited recipient, nil if the gateway is rate limited.
+	To *PhoneNumber
+	// RetryAfter How long to wait before sending again.
+	RetryAfter time.Duration
+}
+
+// NewErrRateLimited New a rate limited error of the gateway or the recipient.
+func NewErrRateLimited(gateway string, to *PhoneNumber, retryAfter time.Duration) *ErrRateLimited {
+	return &ErrRateLimited{Gateway: gateway, To: to, RetryAfter: retryAfter}
+}
+
+func (e *ErrRateLimited) Error() string {
+	if e.Gateway != "" {
+		return fmt.Sprintf("gateway %s rate limited, retry after %s", e.Gateway, e.RetryAfter)
+	}
+
+	return fmt.Sprintf("recipient %s rate limited, retry after %s", e.To, e.RetryAfter)
+}
+
+// ErrorCode Provider-neutral code of a send error.
+type ErrorCode int
+
+const (
+	// ErrorCodeUnknown the error can not be classified.
+	ErrorCodeUnknown ErrorCode = iota
+	// ErrorCodeInvalidNumber the phone number is invalid or can not receive messages.
+	ErrorCodeInvalidNumber
+	// ErrorCodeTemplateNotApproved the template does not exist, is not approved or its params are invalid.
+	ErrorCodeTemplateNotApproved
+	// ErrorCodeSignatureInvalid the signature does not exist or is not approved.
+	ErrorCodeSignatureInvalid
+	// ErrorCodeRateLimited the provider rejected the request because of frequency limits.
+	ErrorCodeRateLimited
+	// ErrorCodeInsufficientBalance the account balance or package is exhausted.
+	ErrorCodeInsufficientBalance
+	// ErrorCodeAuthFailed the credentials are invalid or have no permission.
+	ErrorCodeAuthFailed
+	// ErrorCodeTransient a temporary failure such as a network timeout or a provider system error.
+	ErrorCodeTransient
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	ErrorCodeUnknown:             "unknown",
+	ErrorCodeInvalidNumber:       "invalid_number",
+	ErrorCodeTemplateNotApproved: "template_not_approved",
+	ErrorCodeSignatureInvalid:    "signature_invalid",
+	ErrorCodeRateLimited:         "rate_limited",
+	ErrorCodeInsufficientBalance: "insufficient_balance",
+	ErrorCodeAuthFailed:          "auth_failed",
+	ErrorCodeTransient:           "transient",
+}
+
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+
+	return errorCodeNames[ErrorCodeUnknown]
+}
+
+// SendError Classified error returned by a gateway.
+type SendError struct {
+	Gateway         string
+	Code            ErrorCode
+	ProviderCode    string
+	ProviderMessage string
+	Err             error
+}
+
+// NewSendError New a send error with the provider code and message.
+func NewSendError(gateway string, code ErrorCode, providerCode, providerMessage string) *SendError {
+	return &SendError{
+		Gateway:         gateway,
+		Code:            code,
+		ProviderCode:    providerCode,
+		ProviderMessage: providerMessage,
+	}
+}
+
+func (e *SendError) Error() string {
+	if e.Err != nil && e.ProviderCode == "" {
+		return fmt.Sprintf("[%s] send failed [%s]: %v", e.Gateway, e.Code, e.Err)
+	}
+
+	return fmt.Sprintf("[%s] send failed [%s]: code: %s, message: %s", e.Gateway, e.Code, e.ProviderCode, e.ProviderMessage)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable Whether sending again later may succeed.
+func (e *SendError) Retryable() bool {
+	return e.Code == ErrorCodeTransient || e.Code == ErrorCodeRateLimited
+}
+
+// ClassifyError Classify an error returned by a gateway.
+func ClassifyError(err error) ErrorCode {
+	if err == nil {
+		return ErrorCodeUnknown
+	}
+
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Code
+	}
+
+	var rateLimitedErr *ErrRateLimited
+	if errors.As(err, &rateLimitedErr) {
+		return ErrorCodeRateLimited
+	}
+
+	var requestErr *ErrRequestFailed
+	if errors.As(err, &requestErr) {
+		switch {
+		case requestErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorCodeRateLimited
+		case requestErr.StatusCode == http.StatusUnauthorized || requestErr.StatusCode == http.StatusForbidden:
+			return ErrorCodeAuthFailed
+		case requestErr.StatusCode >= http.StatusInternalServerError:
+			return ErrorCodeTransient
+		}
+
+		return ErrorCodeUnknown
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorCodeTransient
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorCodeTransient
+	}
+
+	return ErrorCodeUnknown
+}
+
+// normalizeError Wrap an error returned by the gateway into a SendError.
+func normalizeError(gateway string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		if sendErr.Gateway == "" {
+			sendErr.Gateway = gateway
+		}
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return &SendError{
+		Gateway: gateway,
+		Code:    ClassifyError(err),
+		Err:     err,
+	}
+}
+
+// isRecipientError Whether the error is caused by the recipient, other gateways will fail too.
+func isRecipientError(err error) bool {
+	return ClassifyError(err) == ErrorCodeInvalidNumber
+}
+
+// isGatewayOutcome Whether the error says something about the gateway.
+// Errors caused by the recipient or the message, canceled sends and local rate limits are not the gateway's fault.
+func isGatewayOutcome(err error) bool {
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rateLimitedErr *ErrRateLimited
+	if errors.As(err, &rateLimitedErr) {
+		return false
+	}
+
+	switch ClassifyError(err) {
+	case ErrorCodeInvalidNumber, ErrorCodeTemplateNotApproved, ErrorCodeSignatureInvalid:
+		return false
+	}
+
+	return true
+}
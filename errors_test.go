@@ -0,0 +1,111 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: ErrorCodeUnknown,
+		},
+		{
+			name: "send error",
+			err:  NewSendError("aliyun", ErrorCodeInvalidNumber, "isv.MOBILE_NUMBER_ILLEGAL", "非法手机号"),
+			want: ErrorCodeInvalidNumber,
+		},
+		{
+			name: "too many requests",
+			err:  &ErrRequestFailed{StatusCode: 429},
+			want: ErrorCodeRateLimited,
+		},
+		{
+			name: "unauthorized",
+			err:  &ErrRequestFailed{StatusCode: 401},
+			want: ErrorCodeAuthFailed,
+		},
+		{
+			name: "bad gateway",
+			err:  &ErrRequestFailed{StatusCode: 502},
+			want: ErrorCodeTransient,
+		},
+		{
+			name: "deadline exceeded",
+			err:  context.DeadlineExceeded,
+			want: ErrorCodeTransient,
+		},
+		{
+			name: "net error",
+			err:  &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			want: ErrorCodeTransient,
+		},
+		{
+			name: "other",
+			err:  errors.New("send failed"),
+			want: ErrorCodeUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestSendError_Retryable(t *testing.T) {
+	assert.True(t, NewSendError("qcloud", ErrorCodeTransient, "InternalError.Timeout", "").Retryable())
+	assert.True(t, NewSendError("qcloud", ErrorCodeRateLimited, "LimitExceeded.PhoneNumberDailyLimit", "").Retryable())
+	assert.False(t, NewSendError("qcloud", ErrorCodeInvalidNumber, "InvalidParameterValue.IncorrectPhoneNumber", "").Retryable())
+	assert.False(t, NewSendError("qcloud", ErrorCodeAuthFailed, "AuthFailure.SignatureFailure", "").Retryable())
+}
+
+func TestGsms_Send_RecipientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", err: NewSendError("a", ErrorCodeInvalidNumber, "isv.MOBILE_NUMBER_ILLEGAL", "非法手机号")},
+		&delayGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}))
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+
+	var failed *ErrGatewaysFailed
+	if assert.ErrorAs(t, err, &failed) {
+		assert.Len(t, failed.Results, 1)
+	}
+}
+
+func TestGsms_Send_NormalizeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", err: context.DeadlineExceeded},
+		&delayGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}))
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var sendErr *SendError
+	if assert.ErrorAs(t, results[0].Error, &sendErr) {
+		assert.Equal(t, "a", sendErr.Gateway)
+		assert.Equal(t, ErrorCodeTransient, sendErr.Code)
+		assert.True(t, sendErr.Retryable())
+	}
+	assert.ErrorIs(t, results[0].Error, context.DeadlineExceeded)
+}
@@ -0,0 +1,212 @@
+package gsms
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptEvent Event of an attempt of a gateway.
+type AttemptEvent struct {
+	Gateway string
+	// Recipient Masked recipient, see PhoneNumber.Masked.
+	Recipient string
+	Template  string
+	// Attempt Attempt number of the gateway, starting from 1.
+	Attempt int
+	// Duration Duration of the attempt, 0 when it starts.
+	Duration time.Duration
+	// Error Error of the attempt, nil unless it failed.
+	Error error
+}
+
+// FailoverEvent Event of failing over to the next gateway after the previous one failed.
+type FailoverEvent struct {
+	From string
+	To   string
+	// Recipient Masked recipient, see PhoneNumber.Masked.
+	Recipient string
+	// Template Template of the failed gateway.
+	Template string
+	// Duration Duration of the attempts of the failed gateway.
+	Duration time.Duration
+	// Error Error of the last attempt of the failed gateway.
+	Error error
+}
+
+// SendExhaustedEvent Event of a send that failed on every gateway.
+type SendExhaustedEvent struct {
+	// Gateway Last gateway tried.
+	Gateway string
+	// Recipient Masked recipient, see PhoneNumber.Masked.
+	Recipient string
+	// Template Template of the last gateway tried.
+	Template string
+	Results  []*Result
+	// Duration Duration of the send.
+	Duration time.Duration
+	Error    error
+}
+
+// eventBus Handlers of the send lifecycle events, shared by the copies of gsms.
+type eventBus struct {
+	mu             sync.RWMutex
+	attemptStart   []func(event *AttemptEvent)
+	attemptSuccess []func(event *AttemptEvent)
+	attemptFailure []func(event *AttemptEvent)
+	failover       []func(event *FailoverEvent)
+	sendExhausted  []func(event *SendExhaustedEvent)
+}
+
+// OnAttemptStart Call the handler before a gateway attempt starts.
+// Handlers are called synchronously on the sending goroutines, they must not block and must be safe for concurrent use.
+func (g *Gsms) OnAttemptStart(handler func(event *AttemptEvent)) {
+	g.events.mu.Lock()
+	defer g.events.mu.Unlock()
+
+	g.events.attemptStart = append(g.events.attemptStart, handler)
+}
+
+// OnAttemptSuccess Call the handler after a gateway attempt succeeded.
+func (g *Gsms) OnAttemptSuccess(handler func(event *AttemptEvent)) {
+	g.events.mu.Lock()
+	defer g.events.mu.Unlock()
+
+	g.events.attemptSuccess = append(g.events.attemptSuccess, handler)
+}
+
+// OnAttemptFailure Call the handler after a gateway attempt failed, including attempts failing before they start,
+// e.g. the gateway is not found or rate limited.
+func (g *Gsms) OnAttemptFailure(handler func(event *AttemptEvent)) {
+	g.events.mu.Lock()
+	defer g.events.mu.Unlock()
+
+	g.events.attemptFailure = append(g.events.attemptFailure, handler)
+}
+
+// OnFailover Call the handler when the send fails over to the next gateway.
+func (g *Gsms) OnFailover(handler func(event *FailoverEvent)) {
+	g.events.mu.Lock()
+	defer g.events.mu.Unlock()
+
+	g.events.failover = append(g.events.failover, handler)
+}
+
+// OnSendExhausted Call the handler when a send failed on every gateway, it is not called if the context is done.
+func (g *Gsms) OnSendExhausted(handler func(event *SendExhaustedEvent)) {
+	g.events.mu.Lock()
+	defer g.events.mu.Unlock()
+
+	g.events.sendExhausted = append(g.events.sendExhausted, handler)
+}
+
+// emitAttemptStart Emit the start of the attempt.
+func (e *eventBus) emitAttemptStart(to *PhoneNumber, result *Result) {
+	e.mu.RLock()
+	handlers := e.attemptStart
+	e.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	event := &AttemptEvent{
+		Gateway:   result.Gateway,
+		Recipient: to.Masked(),
+		Template:  result.Template,
+		Attempt:   result.Attempt,
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// emitAttemptEnd Emit the success or failure of the attempt.
+func (e *eventBus) emitAttemptEnd(to *PhoneNumber, result *Result) {
+	e.mu.RLock()
+	handlers := e.attemptSuccess
+	if result.Status != StatusSuccess {
+		handlers = e.attemptFailure
+	}
+	e.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	event := &AttemptEvent{
+		Gateway:   result.Gateway,
+		Recipient: to.Masked(),
+		Template:  result.Template,
+		Attempt:   result.Attempt,
+		Duration:  result.Latency,
+		Error:     result.Error,
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// emitFailover Emit failing over from the failed attempts of a gateway to the next one.
+func (e *eventBus) emitFailover(to *PhoneNumber, attempts []*Result, next string) {
+	e.mu.RLock()
+	handlers := e.failover
+	e.mu.RUnlock()
+
+	if len(handlers) == 0 || len(attempts) == 0 {
+		return
+	}
+
+	last := attempts[len(attempts)-1]
+
+	event := &FailoverEvent{
+		From:      last.Gateway,
+		To:        next,
+		Recipient: to.Masked(),
+		Template:  last.Template,
+		Duration:  totalLatency(attempts),
+		Error:     last.Error,
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// emitSendExhausted Emit the failure of a send on every gateway.
+func (e *eventBus) emitSendExhausted(to *PhoneNumber, results []*Result, duration time.Duration, err error) {
+	e.mu.RLock()
+	handlers := e.sendExhausted
+	e.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return
+	}
+
+	event := &SendExhaustedEvent{
+		Recipient: to.Masked(),
+		Results:   results,
+		Duration:  duration,
+		Error:     err,
+	}
+
+	if len(results) > 0 {
+		event.Gateway = results[len(results)-1].Gateway
+		event.Template = results[len(results)-1].Template
+	}
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// totalLatency Sum of the latencies of the results.
+func totalLatency(results []*Result) time.Duration {
+	var total time.Duration
+	for _, result := range results {
+		total += result.Latency
+	}
+
+	return total
+}
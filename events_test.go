@@ -0,0 +1,114 @@
+package gsms
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+// eventRecorder Record the events as strings.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *eventRecorder) record(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *eventRecorder) subscribe(g *Gsms) {
+	g.OnAttemptStart(func(event *AttemptEvent) {
+		r.record("start %s#%d %s %s", event.Gateway, event.Attempt, event.Recipient, event.Template)
+	})
+	g.OnAttemptSuccess(func(event *AttemptEvent) {
+		r.record("success %s#%d", event.Gateway, event.Attempt)
+	})
+	g.OnAttemptFailure(func(event *AttemptEvent) {
+		r.record("failure %s#%d %v", event.Gateway, event.Attempt, errors.Unwrap(event.Error))
+	})
+	g.OnFailover(func(event *FailoverEvent) {
+		r.record("failover %s->%s %v", event.From, event.To, errors.Unwrap(event.Error))
+	})
+	g.OnSendExhausted(func(event *SendExhaustedEvent) {
+		r.record("exhausted %s %s %d", event.Gateway, event.Recipient, len(event.Results))
+	})
+}
+
+func TestGsms_Send_Events(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&flakyGateway{name: "a", failures: 100, err: errors.New("timeout")},
+		&flakyGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}))
+
+	recorder := &eventRecorder{}
+	recorder.subscribe(g)
+
+	_, err := g.Send(NewPhoneNumber(18888888888, "86"), newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"start a#1 +86188****8888 SMS_00000001",
+		"failure a#1 timeout",
+		"failover a->b timeout",
+		"start b#1 +86188****8888 SMS_00000001",
+		"success b#1",
+	}, recorder.events)
+}
+
+func TestGsms_Send_Events_Exhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&flakyGateway{name: "a", failures: 100, err: errors.New("timeout")},
+	}, WithGateways([]string{"a", "c"}))
+
+	recorder := &eventRecorder{}
+	recorder.subscribe(g)
+
+	_, err := g.Send(NewPhoneNumber(18888888888, "86"), newAnyMessage(ctrl))
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"start a#1 +86188****8888 SMS_00000001",
+		"failure a#1 timeout",
+		"failover a->c timeout",
+		"failure c#1 <nil>",
+		"exhausted c +86188****8888 2",
+	}, recorder.events)
+}
+
+func TestGsms_SendBatch_Events(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&testBatchGateway{size: 10, failure: map[int]bool{18888888881: true}},
+	}, WithGateways([]string{"batch"}))
+
+	recorder := &eventRecorder{}
+	recorder.subscribe(g)
+
+	_, err := g.SendBatch([]*PhoneNumber{
+		NewPhoneNumber(18888888880, "86"),
+		NewPhoneNumber(18888888881, "86"),
+	}, newAnyMessage(ctrl))
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"start batch#1 +86188****8880 SMS_00000001",
+		"start batch#1 +86188****8881 SMS_00000001",
+		"success batch#1",
+		"failure batch#1 send failed",
+		"exhausted batch +86188****8881 1",
+	}, recorder.events)
+}
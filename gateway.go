@@ -0,0 +1,84 @@
+package gsms
+
+import (
+	"context"
+)
+
+// Receipt Provider response of a sent message.
+type Receipt struct {
+	// MessageID Provider message id, e.g. aliyun BizId, qcloud SerialNo, yunpian sid.
+	MessageID string
+	// RequestID Provider request id.
+	RequestID string
+	// Segments Billed segments.
+	Segments int
+	// Fee Billed fee in Currency.
+	Fee float64
+	// Currency Currency of Fee.
+	Currency string
+	// Country ISO country code reported by the provider.
+	Country string
+	// Raw Decoded provider response.
+	Raw interface{}
+}
+
+// NamedGateway Gateway known only by name, used to evaluate the per gateway content of a message before sending.
+// Sending with it fails with ErrGatewayNotFound.
+type NamedGateway string
+
+func (n NamedGateway) Name() string {
+	return string(n)
+}
+
+func (n NamedGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	return ErrGatewayNotFound
+}
+
+// AdaptGateway Adapt a Gateway to ContextGateway.
+// Gateways that already implement ContextGateway are returned as is.
+func AdaptGateway(gateway Gateway) ContextGateway {
+	if gw, ok := gateway.(ContextGateway); ok {
+		return gw
+	}
+
+	return &contextGateway{Gateway: gateway}
+}
+
+// contextGateway wraps a Gateway without context support.
+type contextGateway struct {
+	Gateway
+}
+
+// SendContext Send a short message, returning as soon as the context is done.
+// The underlying Send cannot be interrupted and finishes in the background.
+func (c *contextGateway) SendContext(ctx context.Context, to *PhoneNumber, message Message, config *Config) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.Gateway.Send(to, message, config)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendWithReceipt Send a short message via the gateway, the receipt is nil if the gateway does not return one.
+func sendWithReceipt(ctx context.Context, gateway Gateway, to *PhoneNumber, message Message, config *Config) (*Receipt, error) {
+	if gw, ok := gateway.(ReceiptGateway); ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return gw.SendWithReceipt(ctx, to, message, config)
+	}
+
+	return nil, AdaptGateway(gateway).SendContext(ctx, to, message, config)
+}
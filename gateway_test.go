@@ -0,0 +1,45 @@
+package gsms
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type slowGateway struct {
+}
+
+func (s *slowGateway) Name() string {
+	return "slow"
+}
+
+func (s *slowGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestNamedGateway(t *testing.T) {
+	gateway := NamedGateway("aliyun")
+	assert.Equal(t, "aliyun", gateway.Name())
+	assert.ErrorIs(t, gateway.Send(NewPhoneNumberWithoutIDDCode(18888888888), nil, nil), ErrGatewayNotFound)
+}
+
+func TestAdaptGateway(t *testing.T) {
+	gw := &Test1Gateway{}
+	assert.Equal(t, &contextGateway{Gateway: gw}, AdaptGateway(gw))
+
+	adapted := AdaptGateway(gw)
+	assert.Same(t, adapted, AdaptGateway(adapted))
+}
+
+func TestAdaptGateway_SendContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := AdaptGateway(&slowGateway{}).SendContext(ctx, NewPhoneNumberWithoutIDDCode(18888888888), nil, nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
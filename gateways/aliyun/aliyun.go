@@ -1,6 +1,7 @@
 package aliyun
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -26,7 +27,12 @@ const EndpointSignatureMethod = "HMAC-SHA1"
 const EndpointSignatureVersion = "1.0"
 const OK = "OK"
 
-var _ gsms.Gateway = (*Gateway)(nil)
+// BatchSize SendSms accepts up to 1000 phone numbers per request.
+const BatchSize = 1000
+
+var _ gsms.ContextGateway = (*Gateway)(nil)
+var _ gsms.ReceiptGateway = (*Gateway)(nil)
+var _ gsms.BatchGateway = (*Gateway)(nil)
 
 type Gateway struct {
 	AccessKeyId     string
@@ -47,7 +53,64 @@ func (g *Gateway) Name() string {
 	return NAME
 }
 
+// Send message.
 func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return g.SendContext(context.Background(), to, message, config)
+}
+
+// SendContext Send message with context.
+func (g *Gateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	_, err := g.SendWithReceipt(ctx, to, message, config)
+	return err
+}
+
+// SendWithReceipt Send message and return the receipt.
+func (g *Gateway) SendWithReceipt(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (*gsms.Receipt, error) {
+	response, err := g.send(ctx, []*gsms.PhoneNumber{to}, message, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.buildReceipt(response), nil
+}
+
+// BatchSize Maximum number of phone numbers per request.
+func (g *Gateway) BatchSize() int {
+	return BatchSize
+}
+
+// SendBatch Send message to multiple phone numbers in one request.
+// Aliyun accepts or rejects the whole request, so there is no per-recipient error.
+func (g *Gateway) SendBatch(ctx context.Context, to []*gsms.PhoneNumber, message gsms.Message, config *gsms.Config) ([]*gsms.BatchStatus, error) {
+	response, err := g.send(ctx, to, message, config)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*gsms.BatchStatus, 0, len(to))
+	for _, phoneNumber := range to {
+		statuses = append(statuses, &gsms.BatchStatus{To: phoneNumber, Receipt: g.buildReceipt(response)})
+	}
+
+	return statuses, nil
+}
+
+// buildReceipt Build receipt from response.
+func (g *Gateway) buildReceipt(response *SendSmsResponse) *gsms.Receipt {
+	return &gsms.Receipt{
+		MessageID: response.BizId,
+		RequestID: response.RequestId,
+		Raw:       response,
+	}
+}
+
+// send Send message to the phone numbers.
+func (g *Gateway) send(ctx context.Context, to []*gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (*SendSmsResponse, error) {
+	phoneNumbers := make([]string, 0, len(to))
+	for _, phoneNumber := range to {
+		phoneNumbers = append(phoneNumbers, phoneNumber.UniversalNumber())
+	}
+
 	query := url.Values{}
 
 	query.Add("RegionId", EndpointRegionId)
@@ -59,22 +122,22 @@ func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.
 	query.Add("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
 	query.Add("Action", EndpointMethod)
 	query.Add("Version", EndpointVersion)
-	query.Add("PhoneNumbers", to.UniversalNumber())
+	query.Add("PhoneNumbers", strings.Join(phoneNumbers, ","))
 	query.Add("SignName", g.SignName)
 
 	template, err := message.GetTemplate(g)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	query.Add("TemplateCode", template)
 
 	data, err := message.GetData(g)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	marshal, err := json.Marshal(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	query.Add("TemplateParam", string(marshal))
 
@@ -82,18 +145,18 @@ func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.
 
 	var response SendSmsResponse
 
-	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger))
+	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger), dove.WithRequestHook(config.RequestHook))
 
-	err = d.Get(EndpointUrl, strings.NewReader(query.Encode()), &response)
+	err = d.GetContext(ctx, EndpointUrl, strings.NewReader(query.Encode()), &response)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if response.Code != OK {
-		return fmt.Errorf("send failed: %+v", response)
+		return nil, newSendError(&response)
 	}
 
-	return nil
+	return &response, nil
 }
 
 // generateSign Generate sign.
@@ -1,10 +1,13 @@
 package aliyun
 
 import (
+	"context"
 	"github.com/jarcoal/httpmock"
 	"github.com/maiqingqiang/gsms"
 	"github.com/maiqingqiang/gsms/message"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
 	"net/url"
 	"testing"
 	"time"
@@ -232,3 +235,168 @@ func TestGateway_Send_Failed(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestGateway_SendContext_Canceled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", `http://dysmsapi.aliyuncs.com`,
+		func(req *http.Request) (*http.Response, error) {
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(200, `{"Message":"发送成功","RequestId":"F69545AD-66DC-53BE-B5BD-0E4D2E147AF1","Code":"OK"}`), nil
+		})
+
+	g := &Gateway{
+		AccessKeyId:     "AccessKeyId",
+		AccessKeySecret: "AccessKeySecret",
+		SignName:        "SignName",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := g.SendContext(
+		ctx,
+		gsms.NewPhoneNumberWithoutIDDCode(188888888888),
+		&message.Message{
+			Template: "SMS_00000001",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGateway_SendBatch(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var phoneNumbers string
+
+	httpmock.RegisterResponder("GET", `http://dysmsapi.aliyuncs.com`,
+		func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			query, _ := url.ParseQuery(string(body))
+			phoneNumbers = query.Get("PhoneNumbers")
+			return httpmock.NewStringResponse(200, `{"Message":"OK","RequestId":"F69545AD-66DC-53BE-B5BD-0E4D2E147AF1","BizId":"900619746936498440^0","Code":"OK"}`), nil
+		})
+
+	g := &Gateway{
+		AccessKeyId:     "AccessKeyId",
+		AccessKeySecret: "AccessKeySecret",
+		SignName:        "SignName",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	to := []*gsms.PhoneNumber{
+		gsms.NewPhoneNumber(18800000001, "86"),
+		gsms.NewPhoneNumber(18800000002, "86"),
+	}
+
+	statuses, err := g.SendBatch(
+		context.Background(),
+		to,
+		&message.Message{
+			Template: "SMS_00000001",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "+8618800000001,+8618800000002", phoneNumbers)
+	assert.Len(t, statuses, 2)
+	assert.NoError(t, statuses[0].Error)
+	assert.NoError(t, statuses[1].Error)
+}
+
+func TestGateway_SendWithReceipt(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", `http://dysmsapi.aliyuncs.com`,
+		httpmock.NewStringResponder(200, `{"Message":"OK","RequestId":"F69545AD-66DC-53BE-B5BD-0E4D2E147AF1","BizId":"900619746936498440^0","Code":"OK"}`))
+
+	g := &Gateway{
+		AccessKeyId:     "AccessKeyId",
+		AccessKeySecret: "AccessKeySecret",
+		SignName:        "SignName",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	receipt, err := g.SendWithReceipt(
+		context.Background(),
+		gsms.NewPhoneNumberWithoutIDDCode(188888888888),
+		&message.Message{
+			Template: "SMS_00000001",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "900619746936498440^0", receipt.MessageID)
+	assert.Equal(t, "F69545AD-66DC-53BE-B5BD-0E4D2E147AF1", receipt.RequestID)
+}
+
+func TestGateway_Send_ClassifyError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", `http://dysmsapi.aliyuncs.com`,
+		httpmock.NewStringResponder(200, `{"Message":"触发小时级流控Permits:5","RequestId":"F69545AD-66DC-53BE-B5BD-0E4D2E147AF1","Code":"isv.BUSINESS_LIMIT_CONTROL"}`))
+
+	g := &Gateway{
+		AccessKeyId:     "AccessKeyId",
+		AccessKeySecret: "AccessKeySecret",
+		SignName:        "SignName",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	err := g.Send(
+		gsms.NewPhoneNumberWithoutIDDCode(188888888888),
+		&message.Message{
+			Template: "SMS_00000001",
+		},
+		config,
+	)
+
+	var sendErr *gsms.SendError
+	if assert.ErrorAs(t, err, &sendErr) {
+		assert.Equal(t, gsms.ErrorCodeRateLimited, sendErr.Code)
+		assert.Equal(t, "isv.BUSINESS_LIMIT_CONTROL", sendErr.ProviderCode)
+		assert.True(t, sendErr.Retryable())
+	}
+}
@@ -0,0 +1,44 @@
+package aliyun
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"strings"
+)
+
+// errorCodes Map aliyun error codes to gsms error codes.
+// https://help.aliyun.com/document_detail/101346.html
+var errorCodes = map[string]gsms.ErrorCode{
+	"isv.MOBILE_NUMBER_ILLEGAL":       gsms.ErrorCodeInvalidNumber,
+	"isv.SMS_TEMPLATE_ILLEGAL":        gsms.ErrorCodeTemplateNotApproved,
+	"isv.TEMPLATE_MISSING_PARAMETERS": gsms.ErrorCodeTemplateNotApproved,
+	"isv.TEMPLATE_PARAMS_ILLEGAL":     gsms.ErrorCodeTemplateNotApproved,
+	"isv.SMS_SIGNATURE_ILLEGAL":       gsms.ErrorCodeSignatureInvalid,
+	"isv.SIGN_NAME_ILLEGAL":           gsms.ErrorCodeSignatureInvalid,
+	"isv.SMS_SIGN_ILLEGAL":            gsms.ErrorCodeSignatureInvalid,
+	"isv.BUSINESS_LIMIT_CONTROL":      gsms.ErrorCodeRateLimited,
+	"isv.DAY_LIMIT_CONTROL":           gsms.ErrorCodeRateLimited,
+	"isv.MONTH_LIMIT_CONTROL":         gsms.ErrorCodeRateLimited,
+	"Throttling.User":                 gsms.ErrorCodeRateLimited,
+	"isv.AMOUNT_NOT_ENOUGH":           gsms.ErrorCodeInsufficientBalance,
+	"isv.OUT_OF_SERVICE":              gsms.ErrorCodeInsufficientBalance,
+	"isv.ACCOUNT_NOT_EXISTS":          gsms.ErrorCodeAuthFailed,
+	"isv.ACCOUNT_ABNORMAL":            gsms.ErrorCodeAuthFailed,
+	"isv.PRODUCT_UN_SUBSCRIPT":        gsms.ErrorCodeAuthFailed,
+	"isv.PRODUCT_UNSUBSCRIBE":         gsms.ErrorCodeAuthFailed,
+	"isp.RAM_PERMISSION_DENY":         gsms.ErrorCodeAuthFailed,
+	"InvalidAccessKeyId.NotFound":     gsms.ErrorCodeAuthFailed,
+	"SignatureDoesNotMatch":           gsms.ErrorCodeAuthFailed,
+	"isp.SYSTEM_ERROR":                gsms.ErrorCodeTransient,
+	"SignatureNonceUsed":              gsms.ErrorCodeTransient,
+	"ServiceUnavailable":              gsms.ErrorCodeTransient,
+}
+
+// newSendError New a classified send error from the response.
+func newSendError(response *SendSmsResponse) *gsms.SendError {
+	code, ok := errorCodes[response.Code]
+	if !ok && strings.HasPrefix(response.Code, "isp.") {
+		code = gsms.ErrorCodeTransient
+	}
+
+	return gsms.NewSendError(NAME, code, response.Code, response.Message)
+}
@@ -0,0 +1,46 @@
+package qcloud
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"strings"
+)
+
+// errorCodes Map qcloud error codes to gsms error codes.
+// https://cloud.tencent.com/document/api/382/55981#6.-.E9.94.99.E8.AF.AF.E7.A0.81
+var errorCodes = map[string]gsms.ErrorCode{
+	"InvalidParameterValue.IncorrectPhoneNumber":               gsms.ErrorCodeInvalidNumber,
+	"FailedOperation.PhoneNumberInBlacklist":                   gsms.ErrorCodeInvalidNumber,
+	"FailedOperation.PhoneNumberParseFail":                     gsms.ErrorCodeInvalidNumber,
+	"FailedOperation.TemplateIncorrectOrUnapproved":            gsms.ErrorCodeTemplateNotApproved,
+	"FailedOperation.TemplateParamSetNotMatchApprovedTemplate": gsms.ErrorCodeTemplateNotApproved,
+	"InvalidParameterValue.TemplateParameterFormatError":       gsms.ErrorCodeTemplateNotApproved,
+	"InvalidParameterValue.TemplateParameterLengthLimit":       gsms.ErrorCodeTemplateNotApproved,
+	"FailedOperation.SignatureIncorrectOrUnapproved":           gsms.ErrorCodeSignatureInvalid,
+	"FailedOperation.InsufficientBalanceInSmsPackage":          gsms.ErrorCodeInsufficientBalance,
+	"RequestLimitExceeded":                                     gsms.ErrorCodeRateLimited,
+	"InternalError.Timeout":                                    gsms.ErrorCodeTransient,
+	"InternalError.RequestTimeException":                       gsms.ErrorCodeTransient,
+}
+
+// errorCodePrefixes Map qcloud error code prefixes to gsms error codes.
+var errorCodePrefixes = map[string]gsms.ErrorCode{
+	"LimitExceeded.":         gsms.ErrorCodeRateLimited,
+	"AuthFailure.":           gsms.ErrorCodeAuthFailed,
+	"UnauthorizedOperation.": gsms.ErrorCodeAuthFailed,
+	"InternalError.":         gsms.ErrorCodeTransient,
+}
+
+// newSendError New a classified send error from the qcloud code and message.
+func newSendError(code, message string) *gsms.SendError {
+	errorCode, ok := errorCodes[code]
+	if !ok {
+		for prefix, c := range errorCodePrefixes {
+			if strings.HasPrefix(code, prefix) {
+				errorCode = c
+				break
+			}
+		}
+	}
+
+	return gsms.NewSendError(NAME, errorCode, code, message)
+}
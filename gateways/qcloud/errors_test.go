@@ -0,0 +1,31 @@
+package qcloud
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_newSendError(t *testing.T) {
+	tests := []struct {
+		code string
+		want gsms.ErrorCode
+	}{
+		{code: "InvalidParameterValue.IncorrectPhoneNumber", want: gsms.ErrorCodeInvalidNumber},
+		{code: "FailedOperation.TemplateIncorrectOrUnapproved", want: gsms.ErrorCodeTemplateNotApproved},
+		{code: "FailedOperation.SignatureIncorrectOrUnapproved", want: gsms.ErrorCodeSignatureInvalid},
+		{code: "LimitExceeded.PhoneNumberDailyLimit", want: gsms.ErrorCodeRateLimited},
+		{code: "FailedOperation.InsufficientBalanceInSmsPackage", want: gsms.ErrorCodeInsufficientBalance},
+		{code: "AuthFailure.SignatureFailure", want: gsms.ErrorCodeAuthFailed},
+		{code: "InternalError.OtherError", want: gsms.ErrorCodeTransient},
+		{code: "FailedOperation.ContainSensitiveWord", want: gsms.ErrorCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := newSendError(tt.code, "message")
+			assert.Equal(t, tt.want, err.Code)
+			assert.Equal(t, tt.code, err.ProviderCode)
+			assert.Equal(t, NAME, err.Gateway)
+		})
+	}
+}
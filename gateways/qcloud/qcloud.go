@@ -2,6 +2,7 @@ package qcloud
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -22,6 +23,9 @@ const EndpointVersion = "2021-01-11"
 const EndpointRegion = "ap-guangzhou"
 const Ok = "Ok"
 
+// BatchSize SendSms accepts up to 200 phone numbers per request.
+const BatchSize = 200
+
 // SendSmsRequest 请求参数 https://cloud.tencent.com/document/api/382/55981
 // https://github.com/TencentCloud/signature-process-demo/blob/main/sms/signature-v3/golang/demo.go
 type SendSmsRequest struct {
@@ -53,17 +57,20 @@ type SendSmsRequest struct {
 	SenderId string `json:"SenderId,omitempty"`
 }
 
+// SendStatus 发送状态 https://cloud.tencent.com/document/api/382/55981#SendStatus
+type SendStatus struct {
+	SerialNo       string `json:"SerialNo"`
+	PhoneNumber    string `json:"PhoneNumber"`
+	Fee            int    `json:"Fee"`
+	SessionContext string `json:"SessionContext"`
+	Code           string `json:"Code"`
+	Message        string `json:"Message"`
+	IsoCode        string `json:"IsoCode"`
+}
+
 type SendSmsResponse struct {
 	Response *struct {
-		SendStatusSet []*struct {
-			SerialNo       string `json:"SerialNo"`
-			PhoneNumber    string `json:"PhoneNumber"`
-			Fee            int    `json:"Fee"`
-			SessionContext string `json:"SessionContext"`
-			Code           string `json:"Code"`
-			Message        string `json:"Message"`
-			IsoCode        string `json:"IsoCode"`
-		} `json:"SendStatusSet"`
+		SendStatusSet []*SendStatus `json:"SendStatusSet"`
 
 		Error *struct {
 			Code    string `json:"Code"`
@@ -71,10 +78,12 @@ type SendSmsResponse struct {
 		} `json:"Error"`
 
 		RequestId string `json:"RequestId"`
-	} `json:"SendSmsResponse"`
+	} `json:"Response"`
 }
 
-var _ gsms.Gateway = (*Gateway)(nil)
+var _ gsms.ContextGateway = (*Gateway)(nil)
+var _ gsms.ReceiptGateway = (*Gateway)(nil)
+var _ gsms.BatchGateway = (*Gateway)(nil)
 
 type Gateway struct {
 	SdkAppId  string
@@ -87,20 +96,154 @@ func (g *Gateway) Name() string {
 	return NAME
 }
 
+// Send message.
 func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
-	phone := fmt.Sprintf("%d", to.Number())
+	return g.SendContext(context.Background(), to, message, config)
+}
+
+// SendContext Send message with context.
+func (g *Gateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	_, err := g.SendWithReceipt(ctx, to, message, config)
+	return err
+}
+
+// SendWithReceipt Send message and return the receipt.
+func (g *Gateway) SendWithReceipt(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (*gsms.Receipt, error) {
+	phone := g.formatPhoneNumber(to)
+
+	response, err := g.send(ctx, []string{phone}, message, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var status *SendStatus
+	for _, s := range response.Response.SendStatusSet {
+		if s.PhoneNumber == phone {
+			status = s
+			break
+		}
+	}
+
+	if status == nil && len(response.Response.SendStatusSet) > 0 {
+		status = response.Response.SendStatusSet[0]
+	}
+
+	if status == nil {
+		return nil, fmt.Errorf("send failed, status of %s not found", phone)
+	}
+
+	if status.Code != Ok {
+		return nil, newSendError(status.Code, status.Message)
+	}
+
+	return g.buildReceipt(response, status), nil
+}
+
+// BatchSize Maximum number of phone numbers per request.
+func (g *Gateway) BatchSize() int {
+	return BatchSize
+}
+
+// SendBatch Send message to multiple phone numbers.
+// Chinese mainland and international phone numbers are sent in separate requests, if a request fails the error
+// is the status of its phone numbers only, the error is returned if every request failed.
+func (g *Gateway) SendBatch(ctx context.Context, to []*gsms.PhoneNumber, message gsms.Message, config *gsms.Config) ([]*gsms.BatchStatus, error) {
+	statuses := make([]*gsms.BatchStatus, len(to))
+
+	var sent bool
+	var lastErr error
+
+	var mainland, international []int
+	for i, phoneNumber := range to {
+		if phoneNumber.IDDCode() == 0 || phoneNumber.InChineseMainland() {
+			mainland = append(mainland, i)
+		} else {
+			international = append(international, i)
+		}
+	}
+
+	for _, group := range [][]int{mainland, international} {
+		if len(group) == 0 {
+			continue
+		}
+
+		phones := make([]string, 0, len(group))
+		for _, i := range group {
+			phones = append(phones, g.formatPhoneNumber(to[i]))
+		}
+
+		response, err := g.send(ctx, phones, message, config)
+		if err != nil {
+			lastErr = err
+
+			for _, i := range group {
+				statuses[i] = &gsms.BatchStatus{To: to[i], Error: err}
+			}
+			continue
+		}
+
+		sent = true
+
+		sendStatuses := make(map[string]*SendStatus, len(response.Response.SendStatusSet))
+		for _, status := range response.Response.SendStatusSet {
+			sendStatuses[status.PhoneNumber] = status
+		}
+
+		for j, i := range group {
+			status, ok := sendStatuses[phones[j]]
+			if !ok && j < len(response.Response.SendStatusSet) {
+				status = response.Response.SendStatusSet[j]
+			}
+
+			statuses[i] = &gsms.BatchStatus{To: to[i]}
+
+			if status == nil {
+				statuses[i].Error = fmt.Errorf("send failed, status of %s not found", phones[j])
+			} else if status.Code != Ok {
+				statuses[i].Error = newSendError(status.Code, status.Message)
+			} else {
+				statuses[i].Receipt = g.buildReceipt(response, status)
+			}
+		}
+	}
+
+	if !sent {
+		return nil, lastErr
+	}
+
+	return statuses, nil
+}
+
+// buildReceipt Build receipt from the send status.
+func (g *Gateway) buildReceipt(response *SendSmsResponse, status *SendStatus) *gsms.Receipt {
+	return &gsms.Receipt{
+		MessageID: status.SerialNo,
+		RequestID: response.Response.RequestId,
+		Segments:  status.Fee,
+		Country:   status.IsoCode,
+		Raw:       response,
+	}
+}
+
+// formatPhoneNumber Format phone number, the IDD code is omitted for numbers without it.
+func (g *Gateway) formatPhoneNumber(to *gsms.PhoneNumber) string {
 	if to.IDDCode() != 0 {
-		phone = to.UniversalNumber()
+		return to.UniversalNumber()
 	}
 
+	return fmt.Sprintf("%d", to.Number())
+}
+
+// send Send message to the phone numbers.
+func (g *Gateway) send(ctx context.Context, phones []string, message gsms.Message, config *gsms.Config) (*SendSmsResponse, error) {
 	template, err := message.GetTemplate(g)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	data, err := message.GetData(g)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	templateParamSet := make([]string, 0, len(data))
@@ -110,16 +253,14 @@ func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.
 	}
 
 	p := &SendSmsRequest{
-		PhoneNumberSet: []string{
-			phone,
-		},
+		PhoneNumberSet:   phones,
 		SmsSdkAppId:      g.SdkAppId,
 		SignName:         g.SignName,
 		TemplateId:       template,
 		TemplateParamSet: templateParamSet,
 	}
 
-	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger))
+	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger), dove.WithRequestHook(config.RequestHook))
 
 	timestamp := time.Now().Unix()
 
@@ -138,23 +279,21 @@ func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.
 
 	var response SendSmsResponse
 
-	err = d.Request(http.MethodPost, EndpointUrl, header, bytes.NewReader(payload), &response)
+	err = d.RequestContext(ctx, http.MethodPost, EndpointUrl, header, bytes.NewReader(payload), &response)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if response.Response.Error != nil && response.Response.Error.Code != "" {
-		return fmt.Errorf("send failed: %+v", response.Response.Error.Message)
+	if response.Response == nil {
+		return nil, fmt.Errorf("send failed: empty response")
 	}
 
-	for _, status := range response.Response.SendStatusSet {
-		if status.Code != Ok {
-			return fmt.Errorf("send failed, status message: %s", status.Message)
-		}
+	if response.Response.Error != nil && response.Response.Error.Code != "" {
+		return nil, newSendError(response.Response.Error.Code, response.Response.Error.Message)
 	}
 
-	return nil
+	return &response, nil
 }
 
 // https://github.com/TencentCloud/signature-process-demo/blob/main/sms/signature-v3/golang/demo.go
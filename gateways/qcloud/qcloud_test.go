@@ -1,9 +1,15 @@
 package qcloud
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/jarcoal/httpmock"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestGateway_generateSign(t *testing.T) {
@@ -31,3 +37,264 @@ func TestGateway_generateSign(t *testing.T) {
 		sign,
 	)
 }
+
+func TestGateway_Send(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		httpmock.NewStringResponder(200, `{"Response":{"SendStatusSet":[{"SerialNo":"5000:1045710669157053657849499619","PhoneNumber":"+8618888888888","Fee":1,"SessionContext":"","Code":"Ok","Message":"send success","IsoCode":"CN"}],"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`))
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	err := g.Send(
+		gsms.NewPhoneNumber(18888888888, "86"),
+		&message.Message{
+			Template: "1111111",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	assert.NoError(t, err)
+}
+
+func TestGateway_SendWithReceipt(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		httpmock.NewStringResponder(200, `{"Response":{"SendStatusSet":[{"SerialNo":"5000:1045710669157053657849499619","PhoneNumber":"+8618888888888","Fee":2,"SessionContext":"","Code":"Ok","Message":"send success","IsoCode":"CN"}],"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`))
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	receipt, err := g.SendWithReceipt(
+		context.Background(),
+		gsms.NewPhoneNumber(18888888888, "86"),
+		&message.Message{
+			Template: "1111111",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "5000:1045710669157053657849499619", receipt.MessageID)
+	assert.Equal(t, "a0aabda6-cf91-4f3e-a81f-9198114a2279", receipt.RequestID)
+	assert.Equal(t, 2, receipt.Segments)
+	assert.Equal(t, "CN", receipt.Country)
+}
+
+func TestGateway_Send_Failed(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		httpmock.NewStringResponder(200, `{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"The provided credentials could not be validated."},"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`))
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	err := g.Send(
+		gsms.NewPhoneNumber(18888888888, "86"),
+		&message.Message{
+			Template: "1111111",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	assert.Error(t, err)
+}
+
+func TestGateway_SendWithReceipt_NoStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		httpmock.NewStringResponder(200, `{"Response":{"SendStatusSet":[],"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`))
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	receipt, err := g.SendWithReceipt(
+		context.Background(),
+		gsms.NewPhoneNumber(18888888888, "86"),
+		&message.Message{Template: "1111111"},
+		config,
+	)
+
+	assert.Error(t, err)
+	assert.Nil(t, receipt)
+}
+
+func TestGateway_SendBatch(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var requests []*SendSmsRequest
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		func(req *http.Request) (*http.Response, error) {
+			var request SendSmsRequest
+			_ = json.NewDecoder(req.Body).Decode(&request)
+			requests = append(requests, &request)
+
+			if len(request.PhoneNumberSet) == 2 {
+				return httpmock.NewStringResponse(200, `{"Response":{"SendStatusSet":[{"SerialNo":"","PhoneNumber":"+8618800000002","Fee":0,"Code":"LimitExceeded.PhoneNumberDailyLimit","Message":"the number of sms messages sent from a single mobile number every day exceeds the upper limit","IsoCode":"CN"},{"SerialNo":"5000:1045710669157053657849499619","PhoneNumber":"+8618800000001","Fee":1,"Code":"Ok","Message":"send success","IsoCode":"CN"}],"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`), nil
+			}
+
+			return httpmock.NewStringResponse(200, `{"Response":{"SendStatusSet":[{"SerialNo":"5000:1045710669157053657849499620","PhoneNumber":"+85261234567","Fee":1,"Code":"Ok","Message":"send success","IsoCode":"HK"}],"RequestId":"b0aabda6-cf91-4f3e-a81f-9198114a2279"}}`), nil
+		})
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	to := []*gsms.PhoneNumber{
+		gsms.NewPhoneNumber(18800000001, "86"),
+		gsms.NewPhoneNumber(61234567, "852"),
+		gsms.NewPhoneNumber(18800000002, "86"),
+	}
+
+	statuses, err := g.SendBatch(
+		context.Background(),
+		to,
+		&message.Message{
+			Template: "1111111",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, []string{"+8618800000001", "+8618800000002"}, requests[0].PhoneNumberSet)
+		assert.Equal(t, []string{"+85261234567"}, requests[1].PhoneNumberSet)
+	}
+
+	assert.Len(t, statuses, 3)
+	assert.NoError(t, statuses[0].Error)
+	assert.NoError(t, statuses[1].Error)
+	assert.Error(t, statuses[2].Error)
+}
+
+func TestGateway_SendBatch_PartialFailure(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	var requests int
+
+	httpmock.RegisterResponder("POST", `https://sms.tencentcloudapi.com`,
+		func(req *http.Request) (*http.Response, error) {
+			requests++
+
+			var request SendSmsRequest
+			_ = json.NewDecoder(req.Body).Decode(&request)
+
+			if request.PhoneNumberSet[0] == "+85261234567" {
+				return httpmock.NewStringResponse(http.StatusBadGateway, `bad gateway`), nil
+			}
+
+			return httpmock.NewStringResponse(200, `{"Response":{"SendStatusSet":[{"SerialNo":"5000:1045710669157053657849499619","PhoneNumber":"+8618800000001","Fee":1,"Code":"Ok","Message":"send success","IsoCode":"CN"}],"RequestId":"a0aabda6-cf91-4f3e-a81f-9198114a2279"}}`), nil
+		})
+
+	g := &Gateway{
+		SdkAppId:  "SdkAppId",
+		SecretId:  "SecretId",
+		SecretKey: "SecretKey",
+		SignName:  "gsms",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	to := []*gsms.PhoneNumber{
+		gsms.NewPhoneNumber(18800000001, "86"),
+		gsms.NewPhoneNumber(61234567, "852"),
+	}
+
+	msg := &message.Message{Template: "1111111", Data: map[string]string{"code": "9527"}}
+
+	statuses, err := g.SendBatch(context.Background(), to, msg, config)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 2, requests)
+
+	if assert.Len(t, statuses, 2) {
+		assert.NoError(t, statuses[0].Error)
+		assert.Equal(t, "5000:1045710669157053657849499619", statuses[0].Receipt.MessageID)
+
+		var requestErr *gsms.ErrRequestFailed
+		assert.ErrorAs(t, statuses[1].Error, &requestErr)
+		assert.Nil(t, statuses[1].Receipt)
+	}
+
+	statuses, err = g.SendBatch(context.Background(), to[1:], msg, config)
+	assert.Error(t, err)
+	assert.Nil(t, statuses)
+}
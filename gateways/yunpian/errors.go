@@ -0,0 +1,58 @@
+package yunpian
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"strconv"
+)
+
+// errorCodes Map yunpian error codes to gsms error codes.
+// https://www.yunpian.com/official/document/sms/zh_cn/returnvalue_common
+var errorCodes = map[int]gsms.ErrorCode{
+	3:   gsms.ErrorCodeInsufficientBalance,
+	5:   gsms.ErrorCodeTemplateNotApproved,
+	7:   gsms.ErrorCodeTemplateNotApproved,
+	8:   gsms.ErrorCodeRateLimited,
+	9:   gsms.ErrorCodeRateLimited,
+	10:  gsms.ErrorCodeInvalidNumber,
+	17:  gsms.ErrorCodeRateLimited,
+	22:  gsms.ErrorCodeRateLimited,
+	24:  gsms.ErrorCodeAuthFailed,
+	33:  gsms.ErrorCodeRateLimited,
+	43:  gsms.ErrorCodeRateLimited,
+	53:  gsms.ErrorCodeRateLimited,
+	-1:  gsms.ErrorCodeAuthFailed,
+	-2:  gsms.ErrorCodeAuthFailed,
+	-3:  gsms.ErrorCodeAuthFailed,
+	-50: gsms.ErrorCodeTransient,
+	-51: gsms.ErrorCodeTransient,
+}
+
+// newSendError New a classified send error from the yunpian code and message.
+func newSendError(code int, msg, detail string) *gsms.SendError {
+	message := msg
+	if detail != "" {
+		message = msg + ": " + detail
+	}
+
+	return gsms.NewSendError(NAME, errorCodes[code], strconv.Itoa(code), message)
+}
+
+// convertRequestError Yunpian responds errors with a non-2xx status code, classify the error by the body.
+func convertRequestError(err error) error {
+	var requestErr *gsms.ErrRequestFailed
+	if !errors.As(err, &requestErr) {
+		return err
+	}
+
+	var response SendSmsResponse
+	if json.Unmarshal([]byte(requestErr.Body), &response) != nil || response.Code == SuccessCode {
+		return err
+	}
+
+	sendErr := newSendError(response.Code, response.Msg, response.Detail)
+	sendErr.Err = err
+
+	return sendErr
+}
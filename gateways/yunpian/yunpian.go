@@ -1,10 +1,13 @@
 package yunpian
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/maiqingqiang/gsms"
 	"github.com/maiqingqiang/gsms/utils/dove"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -23,7 +26,18 @@ const MethodSingleSend = "single_send"
 // MethodTplSingleSend https://www.yunpian.com/official/document/sms/zh_CN/domestic_tpl_single_send
 const MethodTplSingleSend = "tpl_single_send"
 
-var _ gsms.Gateway = (*Gateway)(nil)
+// MethodBatchSend https://www.yunpian.com/official/document/sms/zh_cn/domestic_batch_send
+const MethodBatchSend = "batch_send"
+
+// MethodTplBatchSend https://www.yunpian.com/official/document/sms/zh_cn/domestic_tpl_batch_send
+const MethodTplBatchSend = "tpl_batch_send"
+
+// BatchSize batch_send accepts up to 1000 phone numbers per request.
+const BatchSize = 1000
+
+var _ gsms.ContextGateway = (*Gateway)(nil)
+var _ gsms.ReceiptGateway = (*Gateway)(nil)
+var _ gsms.BatchGateway = (*Gateway)(nil)
 
 type Gateway struct {
 	ApiKey    string
@@ -31,35 +45,160 @@ type Gateway struct {
 }
 
 type SendSmsResponse struct {
-	Code   int    `json:"code"`   // 系统返回码
-	Msg    string `json:"msg"`    // 例如""发送成功""，或者相应错误信息
-	Detail string `json:"detail"` // 具体错误描述或解决方法
+	Code   int         `json:"code"`   // 系统返回码
+	Msg    string      `json:"msg"`    // 例如""发送成功""，或者相应错误信息
+	Detail string      `json:"detail"` // 具体错误描述或解决方法
+	Mobile string      `json:"mobile"` // 接收的手机号
+	Count  int         `json:"count"`  // 成功发送的短信计费条数
+	Fee    json.Number `json:"fee"`    // 扣费金额，数字或 "0.0500" 形式的字符串
+	Unit   string      `json:"unit"`   // 计费单位
+	Sid    int64       `json:"sid"`    // 短信 id
+}
+
+type BatchSendSmsResponse struct {
+	Code       int                `json:"code"`        // 系统返回码，请求失败时返回
+	Msg        string             `json:"msg"`         // 错误信息，请求失败时返回
+	Detail     string             `json:"detail"`      // 具体错误描述或解决方法，请求失败时返回
+	TotalCount int                `json:"total_count"` // 成功发送的短信计费条数
+	TotalFee   json.Number        `json:"total_fee"`   // 扣费金额，数字或 "0.0500" 形式的字符串
+	Unit       string             `json:"unit"`        // 计费单位
+	Data       []*SendSmsResponse `json:"data"`        // 每个手机号的发送结果
 }
 
 // Send message.
-func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (err error) {
+func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return g.SendContext(context.Background(), to, message, config)
+}
+
+// SendContext Send message with context.
+func (g *Gateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	_, err := g.SendWithReceipt(ctx, to, message, config)
+	return err
+}
+
+// SendWithReceipt Send message and return the receipt.
+func (g *Gateway) SendWithReceipt(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (*gsms.Receipt, error) {
+	method, p, err := g.buildParams(to.UniversalNumber(), message, MethodSingleSend, MethodTplSingleSend)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := g.buildEndpoint(ProductSms, ResourceSms, method)
+
+	var response SendSmsResponse
+
+	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger), dove.WithRequestHook(config.RequestHook))
+
+	err = d.PostFormContext(ctx, endpoint, strings.NewReader(p.Encode()), &response)
+	if err != nil {
+		return nil, convertRequestError(err)
+	}
+
+	if response.Code != SuccessCode {
+		return nil, newSendError(response.Code, response.Msg, response.Detail)
+	}
+
+	return g.buildReceipt(&response), nil
+}
+
+// BatchSize Maximum number of phone numbers per request.
+func (g *Gateway) BatchSize() int {
+	return BatchSize
+}
+
+// SendBatch Send message to multiple phone numbers in one request.
+func (g *Gateway) SendBatch(ctx context.Context, to []*gsms.PhoneNumber, message gsms.Message, config *gsms.Config) ([]*gsms.BatchStatus, error) {
+	mobiles := make([]string, 0, len(to))
+	for _, phoneNumber := range to {
+		mobiles = append(mobiles, phoneNumber.UniversalNumber())
+	}
+
+	method, p, err := g.buildParams(strings.Join(mobiles, ","), message, MethodBatchSend, MethodTplBatchSend)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := g.buildEndpoint(ProductSms, ResourceSms, method)
+
+	var response BatchSendSmsResponse
+
+	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger), dove.WithRequestHook(config.RequestHook))
+
+	err = d.PostFormContext(ctx, endpoint, strings.NewReader(p.Encode()), &response)
+	if err != nil {
+		return nil, convertRequestError(err)
+	}
+
+	if response.Code != SuccessCode {
+		return nil, newSendError(response.Code, response.Msg, response.Detail)
+	}
+
+	data := make(map[string]*SendSmsResponse, len(response.Data))
+	for _, item := range response.Data {
+		data[item.Mobile] = item
+	}
+
+	statuses := make([]*gsms.BatchStatus, 0, len(to))
+
+	for i, phoneNumber := range to {
+		status := &gsms.BatchStatus{To: phoneNumber}
+
+		item, ok := data[mobiles[i]]
+		if !ok && i < len(response.Data) {
+			item = response.Data[i]
+		}
+
+		if item == nil {
+			status.Error = fmt.Errorf("send failed, status of %s not found", mobiles[i])
+		} else if item.Code != SuccessCode {
+			status.Error = newSendError(item.Code, item.Msg, item.Detail)
+		} else {
+			status.Receipt = g.buildReceipt(item)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// buildReceipt Build receipt from response.
+func (g *Gateway) buildReceipt(response *SendSmsResponse) *gsms.Receipt {
+	// The fee is informational, a malformed one must not fail a sent message.
+	fee, _ := response.Fee.Float64()
+
+	return &gsms.Receipt{
+		MessageID: strconv.FormatInt(response.Sid, 10),
+		Segments:  response.Count,
+		Fee:       fee,
+		Currency:  response.Unit,
+		Raw:       response,
+	}
+}
+
+// buildParams Build request params, the template method is used when the message has a template.
+func (g *Gateway) buildParams(mobile string, message gsms.Message, method, tplMethod string) (string, url.Values, error) {
 	p := url.Values{}
-	method := MethodSingleSend
 	p.Add("apikey", g.ApiKey)
-	p.Add("mobile", to.UniversalNumber())
+	p.Add("mobile", mobile)
 
 	template, err := message.GetTemplate(g)
 	if err != nil {
-		return
+		return "", nil, err
 	}
 
 	data, err := message.GetData(g)
 	if err != nil {
-		return
+		return "", nil, err
 	}
 
 	content, err := message.GetContent(g)
 	if err != nil {
-		return
+		return "", nil, err
 	}
 
 	if template != "" {
-		method = MethodTplSingleSend
+		method = tplMethod
 		p.Add("tpl_id", template)
 		p.Add("tpl_value", g.buildTplVal(data))
 	} else {
@@ -70,22 +209,7 @@ func (g *Gateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.
 		p.Add("text", content)
 	}
 
-	endpoint := g.buildEndpoint(ProductSms, ResourceSms, method)
-
-	var response SendSmsResponse
-
-	d := dove.New(dove.WithTimeout(config.Timeout), dove.WithLogger(config.Logger))
-
-	err = d.PostForm(endpoint, strings.NewReader(p.Encode()), &response)
-	if err != nil {
-		return err
-	}
-
-	if response.Code != SuccessCode {
-		return fmt.Errorf("send failed code:%d msg:%s detail:%s", response.Code, response.Msg, response.Detail)
-	}
-
-	return
+	return method, p, nil
 }
 
 // buildEndpoint Build endpoint url.
@@ -1,6 +1,7 @@
 package yunpian
 
 import (
+	"context"
 	"github.com/jarcoal/httpmock"
 	"github.com/maiqingqiang/gsms"
 	"github.com/maiqingqiang/gsms/message"
@@ -236,3 +237,121 @@ func Test_buildTplVal(t *testing.T) {
 		})
 	}
 }
+
+func TestGateway_SendBatch(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.yunpian.com/v2/sms/tpl_batch_send.json`,
+		httpmock.NewStringResponder(200, `{"total_count":1,"total_fee":"0.0500","unit":"RMB","data":[{"code":0,"msg":"发送成功","count":1,"fee":"0.0500","unit":"RMB","mobile":"+8618800000001","sid":74712264988},{"code":2,"msg":"请求参数格式错误","count":0,"fee":"0.0000","unit":"RMB","mobile":"+8618800000002","sid":0}]}`))
+
+	g := &Gateway{
+		ApiKey:    "ApiKey",
+		Signature: "Signature",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	to := []*gsms.PhoneNumber{
+		gsms.NewPhoneNumber(18800000001, "86"),
+		gsms.NewPhoneNumber(18800000002, "86"),
+	}
+
+	statuses, err := g.SendBatch(
+		context.Background(),
+		to,
+		&message.Message{
+			Template: "5532011",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, statuses, 2)
+	assert.Same(t, to[0], statuses[0].To)
+	assert.NoError(t, statuses[0].Error)
+	assert.Equal(t, 0.05, statuses[0].Receipt.Fee)
+	assert.Same(t, to[1], statuses[1].To)
+	assert.Error(t, statuses[1].Error)
+}
+
+func TestGateway_SendWithReceipt(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.yunpian.com/v2/sms/tpl_single_send.json`,
+		httpmock.NewStringResponder(200, `{"code":0,"msg":"发送成功","count":1,"fee":0.05,"unit":"RMB","mobile":"18888888888","sid":74712264988}`))
+
+	g := &Gateway{
+		ApiKey:    "ApiKey",
+		Signature: "Signature",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	receipt, err := g.SendWithReceipt(
+		context.Background(),
+		gsms.NewPhoneNumberWithoutIDDCode(18888888888),
+		&message.Message{
+			Template: "5532011",
+			Data: map[string]string{
+				"code": "9527",
+			},
+		},
+		config,
+	)
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "74712264988", receipt.MessageID)
+	assert.Equal(t, 1, receipt.Segments)
+	assert.Equal(t, 0.05, receipt.Fee)
+	assert.Equal(t, "RMB", receipt.Currency)
+}
+
+func TestGateway_Send_ClassifyError(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", `https://sms.yunpian.com/v2/sms/single_send.json`,
+		httpmock.NewStringResponder(400, `{"http_status_code":400,"code":3,"msg":"账户余额不足","detail":"账户需要充值，请充值后重试"}`))
+
+	g := &Gateway{
+		ApiKey:    "ApiKey",
+		Signature: "Signature",
+	}
+
+	config := &gsms.Config{
+		Timeout: 5 * time.Second,
+		Logger:  gsms.NewLogger().LogMode(gsms.Info),
+	}
+
+	err := g.Send(
+		gsms.NewPhoneNumberWithoutIDDCode(18888888888),
+		&message.Message{
+			Content: "【Gsms】您的验证码是521410",
+		},
+		config,
+	)
+
+	var sendErr *gsms.SendError
+	if assert.ErrorAs(t, err, &sendErr) {
+		assert.Equal(t, gsms.ErrorCodeInsufficientBalance, sendErr.Code)
+		assert.Equal(t, "3", sendErr.ProviderCode)
+		assert.False(t, sendErr.Retryable())
+	}
+}
@@ -1,8 +1,10 @@
 package gsms
 
 import (
+	"context"
 	"fmt"
 	"github.com/maiqingqiang/gsms/strategies"
+	"sort"
 	"strconv"
 	"time"
 )
@@ -12,12 +14,52 @@ type Gsms struct {
 	defaultGateways []string
 	strategy        Strategy
 	gateways        map[string]Gateway
+	sendMode        SendMode
+	hedgeDelay      time.Duration
+
+	defaultRetryPolicy   *RetryPolicy
+	gatewayRetryPolicies map[string]*RetryPolicy
+
+	healthTracker HealthTracker
+
+	costEstimator CostEstimator
+
+	rateLimitStore      RateLimitStore
+	gatewayRateLimits   map[string]*RateLimit
+	recipientRateLimits []*WindowLimit
+
+	idempotencyStore  IdempotencyStore
+	idempotencyWindow time.Duration
+	inflight          *inflightCalls
+
+	scheduler *scheduler
+
+	sendWindowPolicy *SendWindowPolicy
+
+	suppressionStore SuppressionStore
+
+	middlewares      []Middleware
+	batchMiddlewares []BatchMiddleware
+	beforeSendHooks  []BeforeSendHook
+	afterSendHooks   []AfterSendHook
+	sendInterceptors []SendInterceptor
+
+	events *eventBus
+}
+
+// sending A message being sent.
+type sending struct {
+	to       *PhoneNumber
+	message  Message
+	strategy Strategy
 }
 
 // Config gsms config.
 type Config struct {
 	Timeout time.Duration
 	Logger  Logger
+	// RequestHook Hook of the HTTP requests of the gateways, nil if there is none.
+	RequestHook RequestHook
 }
 
 // StatusSuccess send message success.
@@ -26,16 +68,30 @@ const StatusSuccess = "success"
 // StatusFailure send message failure.
 const StatusFailure = "failure"
 
+// StatusDeferred send message deferred to the next send window.
+const StatusDeferred = "deferred"
+
+// StatusSuppressed send message skipped, the recipient is suppressed.
+const StatusSuppressed = "suppressed"
+
 // Result Gateway send message result.
 type Result struct {
 	Gateway  string
 	Status   string
 	Template string
 	Error    error
+	// Attempt Attempt number of the gateway, starting from 1.
+	Attempt int
+	// Receipt Provider receipt, nil if the gateway does not return one.
+	Receipt *Receipt
+	// Latency Duration of the attempt.
+	Latency time.Duration
+	// Cost Estimated cost of the attempt, 0 if unknown.
+	Cost float64
 }
 
 func (r *Result) String() string {
-	return fmt.Sprintf("gateway: %s, status: %s, template: %s, error: %v", r.Gateway, r.Status, r.Template, r.Error)
+	return fmt.Sprintf("gateway: %s, status: %s, template: %s, latency: %s, error: %v", r.Gateway, r.Status, r.Template, r.Latency, r.Error)
 }
 
 // New a gsms instance.
@@ -51,8 +107,23 @@ func New(gateways []Gateway, options ...Option) *Gsms {
 			Timeout: 5 * time.Second,
 			Logger:  NewLogger(),
 		},
-		gateways: gatewaysMap,
-		strategy: &strategies.OrderStrategy{},
+		gateways:   gatewaysMap,
+		strategy:   &strategies.OrderStrategy{},
+		sendMode:   SendModeSequential,
+		hedgeDelay: time.Second,
+
+		gatewayRetryPolicies: map[string]*RetryPolicy{},
+
+		rateLimitStore:    NewMemoryRateLimitStore(),
+		gatewayRateLimits: map[string]*RateLimit{},
+
+		idempotencyStore:  NewMemoryIdempotencyStore(),
+		idempotencyWindow: DefaultIdempotencyWindow,
+		inflight:          &inflightCalls{calls: map[string]*idempotentCall{}},
+
+		scheduler: newScheduler(),
+
+		events: &eventBus{},
 	}
 
 	for _, option := range options {
@@ -64,94 +135,206 @@ func New(gateways []Gateway, options ...Option) *Gsms {
 
 // Send a message.
 func (g *Gsms) Send(to interface{}, message Message, gateways ...string) ([]*Result, error) {
+	return g.SendContext(context.Background(), to, message, gateways...)
+}
 
-	if len(gateways) == 0 {
-		var err error
-		gateways, err = message.Gateways()
-		if err != nil {
-			return nil, err
-		}
+// SendContext Send a message with context.
+// The failover stops as soon as the context is done.
+// A message with an idempotency key, see IdempotentMessage and ContextWithIdempotencyKey, is only sent once in
+// the idempotency window, repeated sends return the results of the first successful one.
+func (g *Gsms) SendContext(ctx context.Context, to interface{}, message Message, gateways ...string) ([]*Result, error) {
+	if key := idempotencyKey(ctx, message); key != "" {
+		return g.sendIdempotent(ctx, key, to, message, gateways)
 	}
 
-	if len(gateways) == 0 {
-		gateways = g.defaultGateways
+	return g.send(ctx, to, message, gateways)
+}
+
+// send Send a message.
+func (g *Gsms) send(ctx context.Context, to interface{}, message Message, gateways []string) ([]*Result, error) {
+
+	phoneNumber, err := parsePhoneNumber(to)
+	if err != nil {
+		return nil, err
 	}
 
-	gateways = g.formatGateways(message, gateways)
+	request := &SendRequest{
+		To:       phoneNumber,
+		Message:  message,
+		Gateways: gateways,
+	}
 
-	var phoneNumber *PhoneNumber
+	return g.intercept(request, func(ctx context.Context) ([]*Result, error) {
+		results, err := g.sendRequest(ctx, request)
 
-	switch to.(type) {
-	case *PhoneNumber:
-		phoneNumber = to.(*PhoneNumber)
-	case int:
-		phoneNumber = NewPhoneNumberWithoutIDDCode(to.(int))
-	case string:
-		number, err := strconv.Atoi(to.(string))
-		if err != nil {
-			return nil, err
-		}
+		g.afterSend(ctx, request, results, err)
 
-		phoneNumber = NewPhoneNumberWithoutIDDCode(number)
-	default:
-		return nil, ErrInvalidPhoneNumber
-	}
+		return results, err
+	})(ctx)
+}
 
-	var results []*Result
-	isSuccessful := false
+// sendRequest Run the before send hooks and send the request.
+func (g *Gsms) sendRequest(ctx context.Context, request *SendRequest) ([]*Result, error) {
+	if err := g.beforeSend(ctx, request); err != nil {
+		return nil, err
+	}
 
-	for _, gateway := range gateways {
+	if err := g.checkSuppression(request.To, request.Message); err != nil {
+		return nil, err
+	}
 
-		result := &Result{
-			Gateway: gateway,
-			Status:  StatusSuccess,
-		}
+	if err := g.checkSendWindow(ctx, request.To, request.Message, request.Gateways); err != nil {
+		return nil, err
+	}
 
-		var gw Gateway
+	gateways, err := g.candidateGateways(request.Message, request.Gateways)
+	if err != nil {
+		return nil, err
+	}
 
-		gw, result.Error = g.Gateway(gateway)
+	if err := g.limitRecipient(request.To); err != nil {
+		return nil, err
+	}
 
-		if result.Error != nil {
-			result.Status = StatusFailure
-			results = append(results, result)
-			continue
-		}
+	s := &sending{
+		to:       request.To,
+		message:  request.Message,
+		strategy: g.strategyOf(request.Message),
+	}
 
-		result.Template, result.Error = message.GetTemplate(gw)
-		if result.Error != nil {
-			result.Status = StatusFailure
-			results = append(results, result)
-			continue
-		}
+	gateways = g.demoteUnhealthy(g.orderGateways(s, gateways))
 
-		g.config.Logger.Infof("[%s] start send [template: %s] message", gateway, result.Template)
+	switch g.sendMode {
+	case SendModeRace, SendModeHedged:
+		return g.sendConcurrently(ctx, s, gateways)
+	default:
+		return g.sendSequentially(ctx, s, gateways)
+	}
+}
 
-		result.Error = gw.Send(phoneNumber, message, g.config)
+// sendSequentially Try gateways one by one until one of them succeeds.
+func (g *Gsms) sendSequentially(ctx context.Context, s *sending, gateways []string) ([]*Result, error) {
+	var results []*Result
+	isSuccessful := false
+	start := time.Now()
 
-		if result.Error != nil {
-			result.Status = StatusFailure
-			g.config.Logger.Warnf("[%s] send [template: %s] message failed: %+v", gateway, result.Template, result.Error)
-		} else {
-			g.config.Logger.Infof("[%s] send [template: %s] message success", gateway, result.Template)
+	for i, gateway := range gateways {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		g.config.Logger.Infof("[%s] end send [template: %s] message\n", gateway, result.Template)
+		attempts := g.attemptWithRetry(ctx, s, gateway)
+		result := attempts[len(attempts)-1]
+
+		results = append(results, attempts...)
 
-		results = append(results, result)
+		if result.Status == StatusFailure && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
 		if result.Status == StatusSuccess {
 			isSuccessful = true
 			break
 		}
+
+		if isRecipientError(result.Error) {
+			g.config.Logger.Warnf("[%s] recipient error, skip other gateways", gateway)
+			break
+		}
+
+		if i+1 < len(gateways) {
+			g.events.emitFailover(s.to, attempts, gateways[i+1])
+		}
 	}
 
 	if !isSuccessful {
-		return nil, NewErrGatewayFailed(results)
+		err := NewErrGatewayFailed(results)
+		g.events.emitSendExhausted(s.to, results, time.Since(start), err)
+		return nil, err
 	}
 
 	return results, nil
 }
 
+// attempt Send the message via the gateway once, n is the attempt number of the gateway.
+func (g *Gsms) attempt(ctx context.Context, s *sending, gateway string, n int) *Result {
+	result := &Result{
+		Gateway: gateway,
+		Status:  StatusSuccess,
+		Attempt: n,
+	}
+
+	defer g.events.emitAttemptEnd(s.to, result)
+
+	var gw Gateway
+
+	gw, result.Error = g.Gateway(gateway)
+
+	if result.Error != nil {
+		result.Status = StatusFailure
+		return result
+	}
+
+	result.Template, result.Error = s.message.GetTemplate(gw)
+	if result.Error != nil {
+		result.Status = StatusFailure
+		return result
+	}
+
+	result.Cost = g.estimateCost(s, gw, s.to)
+
+	g.events.emitAttemptStart(s.to, result)
+
+	if result.Error = g.limitGateway(gateway); result.Error != nil {
+		result.Error = normalizeError(gateway, result.Error)
+		result.Status = StatusFailure
+		g.config.Logger.Warnf("[%s] send [template: %s] message skipped: %+v", gateway, result.Template, result.Error)
+		return result
+	}
+
+	g.config.Logger.Infof("[%s] start send [template: %s] message", gateway, result.Template)
+
+	g.probeHealth(gateway)
+
+	start := time.Now()
+	result.Receipt, result.Error = g.chain(sendWithReceipt)(ctx, gw, s.to, s.message, g.config)
+	result.Latency = time.Since(start)
+	result.Error = normalizeError(gateway, result.Error)
+
+	g.observe(s, gateway, result.Latency, result.Error)
+	g.observeRoute(s, s.to, gateway, result.Latency, result.Error)
+
+	if result.Error != nil {
+		result.Status = StatusFailure
+		g.config.Logger.Warnf("[%s] send [template: %s] message failed: %+v", gateway, result.Template, result.Error)
+	} else {
+		g.config.Logger.Infof("[%s] send [template: %s] message success", gateway, result.Template)
+	}
+
+	g.config.Logger.Infof("[%s] end send [template: %s] message\n", gateway, result.Template)
+
+	return result
+}
+
+// parsePhoneNumber Parse *PhoneNumber, int and string into a phone number.
+func parsePhoneNumber(to interface{}) (*PhoneNumber, error) {
+	switch to.(type) {
+	case *PhoneNumber:
+		return to.(*PhoneNumber), nil
+	case int:
+		return NewPhoneNumberWithoutIDDCode(to.(int)), nil
+	case string:
+		number, err := strconv.Atoi(to.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPhoneNumberWithoutIDDCode(number), nil
+	default:
+		return nil, ErrInvalidPhoneNumber
+	}
+}
+
 // Gateway Get gateway by name
 func (g *Gsms) Gateway(name string) (Gateway, error) {
 	if gateway, ok := g.gateways[name]; ok {
@@ -161,18 +344,102 @@ func (g *Gsms) Gateway(name string) (Gateway, error) {
 	return nil, ErrGatewayNotFound
 }
 
-// formatGateways format gateways
-func (g *Gsms) formatGateways(message Message, gateways []string) []string {
+// GatewayNames Names of the registered gateways sorted by name.
+func (g *Gsms) GatewayNames() []string {
+	names := make([]string, 0, len(g.gateways))
+	for name := range g.gateways {
+		names = append(names, name)
+	}
 
-	if strategy, err := message.Strategy(); err == nil && strategy != nil {
+	sort.Strings(names)
+
+	return names
+}
+
+// candidateGateways Get the gateways the message can be sent with.
+func (g *Gsms) candidateGateways(message Message, gateways []string) ([]string, error) {
+	if len(gateways) == 0 {
+		var err error
+		gateways, err = message.Gateways()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(gateways) == 0 {
+		gateways = g.defaultGateways
+	}
+
+	return gateways, nil
+}
+
+// orderGateways Order the gateways by the strategy, routing strategies also take the recipient into account.
+func (g *Gsms) orderGateways(s *sending, gateways []string) []string {
+	switch strategy := s.strategy.(type) {
+	case RoutingStrategy:
+		return strategy.Route(s.to, s.message, gateways)
+	case nil:
+		return gateways
+	default:
 		return strategy.Apply(gateways)
 	}
+}
 
-	if g.strategy != nil {
-		return g.strategy.Apply(gateways)
+// demoteUnhealthy Move unhealthy gateways to the end.
+func (g *Gsms) demoteUnhealthy(gateways []string) []string {
+	if g.healthTracker == nil {
+		return gateways
 	}
 
-	return gateways
+	return (&strategies.HealthStrategy{Health: g.healthTracker}).Apply(gateways)
+}
+
+// strategyOf Get the strategy of the message, the message strategy overrides the default one.
+func (g *Gsms) strategyOf(message Message) Strategy {
+	if strategy, err := message.Strategy(); err == nil && strategy != nil {
+		return strategy
+	}
+
+	return g.strategy
+}
+
+// observe Feed the outcome of an attempt back to the health tracker and the strategy.
+func (g *Gsms) observe(s *sending, gateway string, latency time.Duration, err error) {
+	if !isGatewayOutcome(err) {
+		return
+	}
+
+	g.recordHealth(gateway, err)
+
+	if strategy, ok := s.strategy.(FeedbackStrategy); ok {
+		strategy.Observe(gateway, latency, err)
+	}
+}
+
+// estimateCost Estimate the cost of sending the message to the recipient, 0 if unknown.
+func (g *Gsms) estimateCost(s *sending, gateway Gateway, to *PhoneNumber) float64 {
+	estimator := g.costEstimator
+	if estimator == nil {
+		estimator, _ = s.strategy.(CostEstimator)
+	}
+
+	if estimator == nil {
+		return 0
+	}
+
+	cost, _ := estimator.EstimateCost(gateway, to, s.message)
+	return cost
+}
+
+// observeRoute Feed the outcome of an attempt for the recipient back to the strategy.
+func (g *Gsms) observeRoute(s *sending, to *PhoneNumber, gateway string, latency time.Duration, err error) {
+	if !isGatewayOutcome(err) {
+		return
+	}
+
+	if strategy, ok := s.strategy.(RoutingFeedbackStrategy); ok {
+		strategy.ObserveRoute(to, gateway, latency, err)
+	}
 }
 
 // Debug set logger level to info
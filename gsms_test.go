@@ -1,6 +1,7 @@
 package gsms
 
 import (
+	"context"
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -119,16 +120,57 @@ func TestGsms_Send(t *testing.T) {
 		return
 	}
 
+	assert.Len(t, result, 1)
+	assert.Positive(t, result[0].Latency)
+	result[0].Latency = 0
+
 	assert.Equal(t, result, []*Result{
 		{
 			Gateway:  "mockGateway",
 			Status:   "success",
 			Template: "SMS_00000001",
 			Error:    nil,
+			Attempt:  1,
 		},
 	})
 }
 
+func TestGsms_Send_Receipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMessage := NewMockMessage(ctrl)
+	mockGateway := NewMockReceiptGateway(ctrl)
+
+	receipt := &Receipt{
+		MessageID: "900619746936498440^0",
+		RequestID: "F69545AD-66DC-53BE-B5BD-0E4D2E147AF1",
+		Segments:  1,
+	}
+
+	mockMessage.EXPECT().Gateways().Return(nil, nil)
+	mockMessage.EXPECT().Strategy().Return(nil, nil)
+	mockMessage.EXPECT().GetTemplate(mockGateway).Return("SMS_00000001", nil)
+
+	mockGateway.EXPECT().Name().Return("mockGateway")
+	mockGateway.EXPECT().SendWithReceipt(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(receipt, nil)
+
+	g := New([]Gateway{
+		mockGateway,
+	}, WithGateways([]string{
+		"mockGateway",
+	}))
+
+	result, err := g.Send(18888888888, mockMessage)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, result, 1) {
+		assert.Same(t, receipt, result[0].Receipt)
+	}
+}
+
 func TestGsms_Send_Failed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -151,3 +193,140 @@ func TestGsms_Send_Failed(t *testing.T) {
 	_, err := g.Debug().Send(18888888888, mockMessage)
 	assert.Error(t, err)
 }
+
+func TestGsms_SendContext_Canceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMessage := NewMockMessage(ctrl)
+	mockGateway := NewMockGateway(ctrl)
+
+	mockMessage.EXPECT().Gateways().Return(nil, nil)
+	mockMessage.EXPECT().Strategy().Return(nil, nil)
+
+	mockGateway.EXPECT().Name().Return("mockGateway")
+
+	g := New([]Gateway{
+		mockGateway,
+	}, WithGateways([]string{
+		"mockGateway",
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := g.Debug().SendContext(ctx, 18888888888, mockMessage)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGsms_SendContext_StopFailover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockMessage := NewMockMessage(ctrl)
+	mockGateway1 := NewMockContextGateway(ctrl)
+	mockGateway2 := NewMockContextGateway(ctrl)
+
+	mockMessage.EXPECT().Gateways().Return(nil, nil)
+	mockMessage.EXPECT().Strategy().Return(nil, nil)
+	mockMessage.EXPECT().GetTemplate(mockGateway1).Return("SMS_00000001", nil)
+
+	mockGateway1.EXPECT().Name().Return("mockGateway1")
+	mockGateway1.EXPECT().SendContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, to *PhoneNumber, message Message, config *Config) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	mockGateway2.EXPECT().Name().Return("mockGateway2")
+
+	g := New([]Gateway{
+		mockGateway1,
+		mockGateway2,
+	}, WithGateways([]string{
+		"mockGateway1",
+		"mockGateway2",
+	}))
+
+	_, err := g.Debug().SendContext(ctx, 18888888888, mockMessage)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGsms_Send_FeedbackStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	strategy := NewMockFeedbackStrategy(ctrl)
+	strategy.EXPECT().Apply([]string{"a", "b"}).Return([]string{"a", "b"})
+	strategy.EXPECT().Observe("a", gomock.Any(), gomock.Not(nil))
+	strategy.EXPECT().Observe("b", gomock.Any(), nil)
+
+	g := New([]Gateway{
+		&flakyGateway{name: "a", failures: 1, err: context.DeadlineExceeded},
+		&flakyGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}), WithStrategy(strategy))
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+}
+
+func TestGsms_Send_FeedbackStrategy_RecipientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	strategy := NewMockFeedbackStrategy(ctrl)
+	strategy.EXPECT().Apply(gomock.Any()).Return([]string{"a"})
+
+	g := New([]Gateway{
+		&flakyGateway{name: "a", failures: 1, err: NewSendError("a", ErrorCodeInvalidNumber, "", "")},
+	}, WithGateways([]string{"a"}), WithStrategy(strategy))
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.Error(t, err)
+}
+
+func TestGsms_Send_RoutingStrategy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	to := NewPhoneNumber(61234567, "852")
+
+	strategy := NewMockRoutingStrategy(ctrl)
+	strategy.EXPECT().Route(to, gomock.Any(), []string{"a", "b"}).Return([]string{"b"})
+
+	g := New([]Gateway{
+		&flakyGateway{name: "a"},
+		&flakyGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}), WithStrategy(strategy))
+
+	results, err := g.Send(to, newAnyMessage(ctrl))
+	if assert.NoError(t, err) && assert.Len(t, results, 1) {
+		assert.Equal(t, "b", results[0].Gateway)
+	}
+}
+
+func TestGsms_Send_Cost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	to := NewPhoneNumber(18888888888, "86")
+	gateway := &flakyGateway{name: "a"}
+
+	estimator := NewMockCostEstimator(ctrl)
+	estimator.EXPECT().EstimateCost(gateway, to, gomock.Any()).Return(0.045, true)
+
+	g := New([]Gateway{gateway}, WithGateways([]string{"a"}), WithCostEstimator(estimator))
+
+	results, err := g.Send(to, newAnyMessage(ctrl))
+	if assert.NoError(t, err) && assert.Len(t, results, 1) {
+		assert.Equal(t, 0.045, results[0].Cost)
+	}
+}
+
+func TestGsms_GatewayNames(t *testing.T) {
+	g := New([]Gateway{&Test2Gateway{}, &Test1Gateway{}})
+
+	assert.Equal(t, []string{"Test1", "Test2"}, g.GatewayNames())
+}
@@ -0,0 +1,249 @@
+package gsms
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState State of a gateway circuit.
+type CircuitState int
+
+const (
+	// CircuitClosed the gateway is healthy.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen the gateway failed too many times and is demoted until the cool-down ends.
+	CircuitOpen
+	// CircuitHalfOpen the cool-down ended, probe sends decide whether the circuit closes again.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// GatewayHealth Health of a gateway.
+type GatewayHealth struct {
+	Gateway             string
+	State               CircuitState
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+}
+
+func (h GatewayHealth) String() string {
+	return fmt.Sprintf("gateway: %s, state: %s, consecutive failures: %d", h.Gateway, h.State, h.ConsecutiveFailures)
+}
+
+var _ ProbingHealthTracker = (*CircuitBreaker)(nil)
+
+// CircuitBreaker Track gateway health with a circuit per gateway name.
+type CircuitBreaker struct {
+	// FailureThreshold Consecutive failures that open the circuit.
+	FailureThreshold int
+	// CoolDown How long the circuit stays open before probe sends are allowed.
+	CoolDown time.Duration
+	// HalfOpenProbes Probe sends allowed in half-open state, the circuit closes after the same number of successes.
+	HalfOpenProbes int
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+// NewCircuitBreaker New a circuit breaker.
+func NewCircuitBreaker(failureThreshold int, coolDown time.Duration, halfOpenProbes int) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		CoolDown:         coolDown,
+		HalfOpenProbes:   halfOpenProbes,
+	}
+}
+
+// Healthy Whether the gateway should be tried in its normal order, it does not change the circuit.
+// After the cool-down the gateway is healthy until the probe sends are reserved by Probe.
+func (c *CircuitBreaker) Healthy(gateway string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, probes := c.state(c.circuit(gateway))
+
+	switch state {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		return probes < c.halfOpenProbes()
+	default:
+		return true
+	}
+}
+
+// Probe Reserve a send of the gateway, the circuit becomes half-open once the cool-down ended.
+// Sends of a demoted gateway after the probes were reserved are counted as probes too.
+func (c *CircuitBreaker) Probe(gateway string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.circuit(gateway)
+
+	state, probes := c.state(cc)
+	if state != CircuitHalfOpen {
+		return
+	}
+
+	if cc.state == CircuitOpen {
+		cc.state = CircuitHalfOpen
+		cc.successes = 0
+	}
+
+	cc.probes = probes + 1
+}
+
+// Success Record a successful send of the gateway.
+func (c *CircuitBreaker) Success(gateway string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.circuit(gateway)
+	cc.failures = 0
+
+	if cc.state == CircuitHalfOpen {
+		cc.successes++
+		if cc.successes >= c.halfOpenProbes() {
+			cc.state = CircuitClosed
+		}
+	} else if cc.state == CircuitOpen {
+		cc.state = CircuitClosed
+	}
+}
+
+// Failure Record a failed send of the gateway.
+func (c *CircuitBreaker) Failure(gateway string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.circuit(gateway)
+	cc.failures++
+
+	if cc.state == CircuitHalfOpen || (cc.state == CircuitClosed && cc.failures >= c.failureThreshold()) {
+		cc.state = CircuitOpen
+		cc.openedAt = c.clock()
+	}
+}
+
+// Health Get the health of the gateway, an open circuit is reported half-open once the cool-down ended.
+func (c *CircuitBreaker) Health(gateway string) GatewayHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cc := c.circuit(gateway)
+	state, _ := c.state(cc)
+
+	return GatewayHealth{
+		Gateway:             gateway,
+		State:               state,
+		ConsecutiveFailures: cc.failures,
+		OpenedAt:            cc.openedAt,
+	}
+}
+
+func (c *CircuitBreaker) circuit(gateway string) *circuit {
+	if c.circuits == nil {
+		c.circuits = make(map[string]*circuit)
+	}
+
+	cc, ok := c.circuits[gateway]
+	if !ok {
+		cc = &circuit{}
+		c.circuits[gateway] = cc
+	}
+
+	return cc
+}
+
+// state State and reserved probes of the circuit now, an open circuit is half-open without probes once the
+// cool-down ended.
+func (c *CircuitBreaker) state(cc *circuit) (CircuitState, int) {
+	if cc.state == CircuitOpen && c.clock().Sub(cc.openedAt) >= c.CoolDown {
+		return CircuitHalfOpen, 0
+	}
+
+	return cc.state, cc.probes
+}
+
+func (c *CircuitBreaker) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+
+	return time.Now()
+}
+
+func (c *CircuitBreaker) failureThreshold() int {
+	if c.FailureThreshold < 1 {
+		return 1
+	}
+
+	return c.FailureThreshold
+}
+
+func (c *CircuitBreaker) halfOpenProbes() int {
+	if c.HalfOpenProbes < 1 {
+		return 1
+	}
+
+	return c.HalfOpenProbes
+}
+
+// GatewayHealth Get the health of all gateways, sorted by gateway name.
+func (g *Gsms) GatewayHealth() []GatewayHealth {
+	names := g.GatewayNames()
+
+	health := make([]GatewayHealth, 0, len(names))
+
+	for _, name := range names {
+		if g.healthTracker == nil {
+			health = append(health, GatewayHealth{Gateway: name, State: CircuitClosed})
+			continue
+		}
+
+		health = append(health, g.healthTracker.Health(name))
+	}
+
+	return health
+}
+
+// probeHealth Reserve a send of the gateway in the health tracker, see ProbingHealthTracker.
+func (g *Gsms) probeHealth(gateway string) {
+	if tracker, ok := g.healthTracker.(ProbingHealthTracker); ok {
+		tracker.Probe(gateway)
+	}
+}
+
+// recordHealth Record the result of a send in the health tracker.
+func (g *Gsms) recordHealth(gateway string, err error) {
+	if g.healthTracker == nil {
+		return
+	}
+
+	if err == nil {
+		g.healthTracker.Success(gateway)
+		return
+	}
+
+	g.healthTracker.Failure(gateway)
+}
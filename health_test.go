@@ -0,0 +1,141 @@
+package gsms
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+
+	c := NewCircuitBreaker(2, time.Minute, 1)
+	c.now = func() time.Time {
+		return now
+	}
+
+	assert.True(t, c.Healthy("aliyun"))
+
+	c.Failure("aliyun")
+	assert.True(t, c.Healthy("aliyun"))
+	assert.Equal(t, CircuitClosed, c.Health("aliyun").State)
+
+	c.Failure("aliyun")
+	assert.False(t, c.Healthy("aliyun"))
+	assert.Equal(t, CircuitOpen, c.Health("aliyun").State)
+	assert.Equal(t, 2, c.Health("aliyun").ConsecutiveFailures)
+
+	now = now.Add(time.Minute)
+
+	// cool-down ended, one probe is allowed
+	assert.True(t, c.Healthy("aliyun"))
+	assert.True(t, c.Healthy("aliyun"))
+	assert.Equal(t, CircuitHalfOpen, c.Health("aliyun").State)
+	c.Probe("aliyun")
+	assert.False(t, c.Healthy("aliyun"))
+
+	// probe failed
+	c.Failure("aliyun")
+	assert.Equal(t, CircuitOpen, c.Health("aliyun").State)
+	assert.False(t, c.Healthy("aliyun"))
+
+	now = now.Add(time.Minute)
+
+	// probe succeeded
+	assert.True(t, c.Healthy("aliyun"))
+	c.Probe("aliyun")
+	c.Success("aliyun")
+	assert.Equal(t, CircuitClosed, c.Health("aliyun").State)
+	assert.Equal(t, 0, c.Health("aliyun").ConsecutiveFailures)
+	assert.True(t, c.Healthy("aliyun"))
+}
+
+func TestGsms_Send_HealthTracker_Probe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	tracker := NewCircuitBreaker(1, time.Minute, 1)
+	tracker.now = func() time.Time {
+		return now
+	}
+	tracker.Failure("b")
+
+	now = now.Add(time.Minute)
+
+	a := &flakyGateway{name: "a"}
+	b := &flakyGateway{name: "b"}
+
+	g := New([]Gateway{a, b},
+		WithGateways([]string{"a", "b"}),
+		WithHealthTracker(tracker),
+	)
+
+	// sends that never reach the half-open gateway do not use its probe
+	for i := 0; i < 3; i++ {
+		_, err := g.Send(18888888888, newAnyMessage(ctrl))
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(3), a.calls)
+	assert.Zero(t, b.calls)
+	assert.True(t, tracker.Healthy("b"))
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl), "b")
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitClosed, tracker.Health("b").State)
+}
+
+func TestGsms_Send_HealthTracker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a := &flakyGateway{name: "a", failures: 100, err: context.DeadlineExceeded}
+	b := &flakyGateway{name: "b"}
+
+	g := New([]Gateway{a, b},
+		WithGateways([]string{"a", "b"}),
+		WithHealthTracker(NewCircuitBreaker(2, time.Minute, 1)),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := g.Send(18888888888, newAnyMessage(ctrl))
+		assert.NoError(t, err)
+	}
+
+	// gateway a is demoted after two failures
+	assert.Equal(t, int32(2), a.calls)
+	assert.Equal(t, int32(3), b.calls)
+
+	assert.Equal(t, []GatewayHealth{
+		{Gateway: "a", State: CircuitOpen, ConsecutiveFailures: 2, OpenedAt: g.GatewayHealth()[0].OpenedAt},
+		{Gateway: "b", State: CircuitClosed},
+	}, g.GatewayHealth())
+}
+
+func TestGsms_Send_HealthTracker_Demoted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracker := NewCircuitBreaker(1, time.Minute, 1)
+	tracker.Failure("a")
+
+	g := New([]Gateway{&flakyGateway{name: "a"}, &flakyGateway{name: "b", failures: 1, err: context.DeadlineExceeded}},
+		WithGateways([]string{"a", "b"}),
+		WithHealthTracker(tracker),
+	)
+
+	// the demoted gateway is still tried when the others failed
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "b", results[0].Gateway)
+	assert.Equal(t, "a", results[1].Gateway)
+	assert.Equal(t, CircuitClosed, tracker.Health("a").State)
+}
@@ -0,0 +1,209 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyWindow How long the results of a send with an idempotency key are returned again.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
+// idempotencySweepInterval Number of Set calls between sweeps of expired keys.
+const idempotencySweepInterval = 1024
+
+// idempotencyKeyContextKey Context key of the idempotency key.
+type idempotencyKeyContextKey struct{}
+
+// ContextWithIdempotencyKey Attach an idempotency key to the sends with the context,
+// it takes precedence over the key of the message.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext Get the idempotency key attached to the context.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// idempotencyKey Idempotency key of the send, empty if it has none.
+func idempotencyKey(ctx context.Context, message Message) string {
+	if key, ok := IdempotencyKeyFromContext(ctx); ok {
+		return key
+	}
+
+	if message, ok := message.(IdempotentMessage); ok {
+		return message.GetIdempotencyKey()
+	}
+
+	return ""
+}
+
+// idempotencyStoreKey Store key of the idempotency key.
+func idempotencyStoreKey(key string) string {
+	return "gsms:idempotency:" + key
+}
+
+// idempotentCall A send with an idempotency key in flight.
+type idempotentCall struct {
+	done    chan struct{}
+	results []*Result
+	err     error
+}
+
+// inflightCalls Sends with an idempotency key in flight, shared by the copies of gsms.
+type inflightCalls struct {
+	mu    sync.Mutex
+	calls map[string]*idempotentCall
+}
+
+// sendIdempotent Send the message unless the key was sent in the window.
+// Concurrent sends with the same key wait for the first one and get its outcome; only successful and deferred
+// sends are stored so a failed send can be retried with the same key. A deferred send is stored until it is due,
+// sending it again before then returns the same ErrSendDeferred. Store errors are logged and let the send through.
+func (g *Gsms) sendIdempotent(ctx context.Context, key string, to interface{}, message Message, gateways []string) ([]*Result, error) {
+	g.inflight.mu.Lock()
+	if call, ok := g.inflight.calls[key]; ok {
+		g.inflight.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.results, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call := &idempotentCall{done: make(chan struct{})}
+	g.inflight.calls[key] = call
+	g.inflight.mu.Unlock()
+
+	defer func() {
+		g.inflight.mu.Lock()
+		delete(g.inflight.calls, key)
+		g.inflight.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	if g.idempotencyStore == nil {
+		call.results, call.err = g.send(ctx, to, message, gateways)
+		return call.results, call.err
+	}
+
+	results, ok, err := g.idempotencyStore.Get(idempotencyStoreKey(key))
+	if err != nil {
+		g.config.Logger.Warnf("[%s] get idempotency key failed: %+v", key, err)
+	} else if ok {
+		g.config.Logger.Infof("[%s] idempotency key already sent, skip sending", key)
+
+		if err := deferredError(results); err != nil {
+			call.err = err
+			return nil, err
+		}
+
+		call.results = results
+		return results, nil
+	}
+
+	call.results, call.err = g.send(ctx, to, message, gateways)
+
+	results, ttl := call.results, g.idempotencyWindow
+
+	var deferred *ErrSendDeferred
+	if errors.As(call.err, &deferred) {
+		results = []*Result{{Status: StatusDeferred, Error: deferred}}
+
+		if until := time.Until(deferred.Handle.At); until < ttl {
+			ttl = until
+		}
+	}
+
+	if (call.err == nil || deferred != nil) && ttl > 0 {
+		if err := g.idempotencyStore.Set(idempotencyStoreKey(key), results, ttl); err != nil {
+			g.config.Logger.Warnf("[%s] set idempotency key failed: %+v", key, err)
+		}
+	}
+
+	return call.results, call.err
+}
+
+// deferredError Error of stored results of a deferred send, nil if the send was not deferred.
+func deferredError(results []*Result) error {
+	if len(results) != 1 || results[0].Status != StatusDeferred {
+		return nil
+	}
+
+	return results[0].Error
+}
+
+var _ IdempotencyStore = (*MemoryIdempotencyStore)(nil)
+
+// MemoryIdempotencyStore In-memory idempotency store, keys are not shared between processes.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	sets    int
+	now     func() time.Time
+}
+
+// idempotencyEntry Results stored for a key.
+type idempotencyEntry struct {
+	results   []*Result
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore New an in-memory idempotency store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: map[string]*idempotencyEntry{},
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryIdempotencyStore) Get(key string) ([]*Result, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !m.now().Before(entry.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.results, true, nil
+}
+
+func (m *MemoryIdempotencyStore) Set(key string, results []*Result, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	m.sets++
+	if m.sets%idempotencySweepInterval == 0 {
+		m.sweep(now)
+	}
+
+	m.entries[key] = &idempotencyEntry{
+		results:   results,
+		expiresAt: now.Add(ttl),
+	}
+
+	return nil
+}
+
+// sweep Drop the expired keys.
+func (m *MemoryIdempotencyStore) sweep(now time.Time) {
+	for key, entry := range m.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
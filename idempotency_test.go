@@ -0,0 +1,199 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countGateway Gateway counting the sends, it waits for release if set.
+type countGateway struct {
+	calls   int32
+	release chan struct{}
+	err     error
+}
+
+func (c *countGateway) Name() string {
+	return "count"
+}
+
+func (c *countGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	atomic.AddInt32(&c.calls, 1)
+
+	if c.release != nil {
+		<-c.release
+	}
+
+	return c.err
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	_, ok, err := store.Get("a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	results := []*Result{{Gateway: "count", Status: StatusSuccess}}
+	assert.NoError(t, store.Set("a", results, time.Minute))
+
+	got, ok, _ := store.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, results, got)
+
+	now = now.Add(time.Minute)
+	_, ok, _ = store.Get("a")
+	assert.False(t, ok)
+}
+
+func TestGsms_Send_IdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}))
+
+	message := NewMockIdempotentMessage(ctrl)
+	message.EXPECT().Gateways().Return(nil, nil).AnyTimes()
+	message.EXPECT().Strategy().Return(nil, nil).AnyTimes()
+	message.EXPECT().GetTemplate(gomock.Any()).Return("SMS_00000001", nil).AnyTimes()
+	message.EXPECT().GetIdempotencyKey().Return("order-1").AnyTimes()
+
+	first, err := g.Send(18888888888, message)
+	assert.NoError(t, err)
+
+	second, err := g.Send(18888888888, message)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(1), gateway.calls)
+	assert.Equal(t, first, second)
+
+	// Sends without a key are not deduplicated.
+	_, err = g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), gateway.calls)
+}
+
+func TestGsms_Send_IdempotencyKey_Failure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{err: errors.New("send failed")}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}))
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order-1")
+
+	_, err := g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	assert.Error(t, err)
+
+	// Failed sends are not stored, the caller can retry with the same key.
+	gateway.err = nil
+	_, err = g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	_, err = g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(2), gateway.calls)
+}
+
+func TestGsms_Send_IdempotencyKey_Concurrent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{release: make(chan struct{})}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}))
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order-1")
+	message := newAnyMessage(ctrl)
+
+	var wg sync.WaitGroup
+	results := make([][]*Result, 5)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var err error
+			results[i], err = g.SendContext(ctx, 18888888888, message)
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&gateway.calls) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(gateway.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), gateway.calls)
+	for _, result := range results {
+		assert.Equal(t, results[0], result)
+	}
+}
+
+func TestGsms_Send_IdempotencyKey_Window(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithIdempotencyStore(store), WithIdempotencyWindow(time.Minute))
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order-1")
+
+	_, _ = g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	_, _ = g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	assert.Equal(t, int32(1), gateway.calls)
+
+	now = now.Add(time.Minute)
+	_, _ = g.SendContext(ctx, 18888888888, newAnyMessage(ctrl))
+	assert.Equal(t, int32(2), gateway.calls)
+}
+
+func TestGsms_Send_IdempotencyKey_Deferred(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	window, start := closedWindow()
+
+	store := NewMemoryScheduleStore(time.Second)
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithScheduleStore(store), WithSendWindowPolicy(&SendWindowPolicy{
+		Default: window,
+		Defer:   true,
+	}))
+	defer g.Close()
+
+	ctx := ContextWithIdempotencyKey(ContextWithLocation(context.Background(), time.UTC), "order-1")
+	message := newCategorizedMessage(ctrl, CategoryMarketing)
+
+	_, err := g.SendContext(ctx, 18888888888, message)
+
+	var first *ErrSendDeferred
+	if !assert.True(t, errors.As(err, &first)) {
+		return
+	}
+
+	// Sending again before the deferred send is due does not schedule another copy.
+	_, err = g.SendContext(ctx, 18888888888, message)
+
+	var second *ErrSendDeferred
+	if assert.True(t, errors.As(err, &second)) {
+		assert.Equal(t, first.Handle.ID, second.Handle.ID)
+		assert.True(t, start.Equal(second.Handle.At))
+	}
+
+	assert.Equal(t, 1, store.Len())
+	assert.Zero(t, atomic.LoadInt32(&gateway.calls))
+}
@@ -2,6 +2,11 @@
 
 package gsms
 
+import (
+	"context"
+	"time"
+)
+
 type Gateway interface {
 	// Name Get gateway name
 	Name() string
@@ -9,6 +14,38 @@ type Gateway interface {
 	Send(to *PhoneNumber, message Message, config *Config) error
 }
 
+// ContextGateway Gateway that supports context.
+type ContextGateway interface {
+	Gateway
+	// SendContext Send a short message with context.
+	SendContext(ctx context.Context, to *PhoneNumber, message Message, config *Config) error
+}
+
+// ReceiptGateway Gateway that returns the provider receipt.
+type ReceiptGateway interface {
+	Gateway
+	// SendWithReceipt Send a short message and return the provider receipt.
+	SendWithReceipt(ctx context.Context, to *PhoneNumber, message Message, config *Config) (*Receipt, error)
+}
+
+// BatchGateway Gateway that can send a message to multiple recipients in one request.
+type BatchGateway interface {
+	Gateway
+	// BatchSize Maximum number of recipients per request.
+	BatchSize() int
+	// SendBatch Send a short message to multiple recipients, the statuses are in the same order as to.
+	SendBatch(ctx context.Context, to []*PhoneNumber, message Message, config *Config) ([]*BatchStatus, error)
+}
+
+// ScheduleGateway Gateway that can ask the provider to send a message at a future time.
+type ScheduleGateway interface {
+	Gateway
+	// SendAt Ask the provider to send a short message at the time and return the provider receipt.
+	SendAt(ctx context.Context, to *PhoneNumber, message Message, at time.Time, config *Config) (*Receipt, error)
+	// CancelScheduled Cancel a short message scheduled at the provider, receipt is the one returned by SendAt.
+	CancelScheduled(ctx context.Context, receipt *Receipt, config *Config) error
+}
+
 // Message interface.
 type Message interface {
 	// Gateways Supported gateways.
@@ -25,11 +62,110 @@ type Message interface {
 	GetType(gateway Gateway) (string, error)
 }
 
+// IdempotentMessage Message carrying an idempotency key, sends with the same key are only sent once.
+type IdempotentMessage interface {
+	Message
+	// GetIdempotencyKey Get the idempotency key, empty if the message has none.
+	GetIdempotencyKey() string
+}
+
+// CategorizedMessage Message with a category, e.g. CategoryOTP, send windows are configured per category.
+type CategorizedMessage interface {
+	Message
+	// GetCategory Get the category, empty if the message has none.
+	GetCategory() string
+}
+
 type Strategy interface {
 	// Apply the strategy and return result.
 	Apply(gateways []string) []string
 }
 
+// RoutingStrategy Strategy that also takes the recipient and message into account.
+type RoutingStrategy interface {
+	Strategy
+	// Route Order the gateways for the recipient and message.
+	Route(to *PhoneNumber, message Message, gateways []string) []string
+}
+
+// RoutingFeedbackStrategy RoutingStrategy that learns from the outcome of every attempt for the recipient.
+type RoutingFeedbackStrategy interface {
+	RoutingStrategy
+	// ObserveRoute Record the outcome of an attempt for the recipient, err is nil on success.
+	ObserveRoute(to *PhoneNumber, gateway string, latency time.Duration, err error)
+}
+
+// FeedbackStrategy Strategy that learns from the outcome of every attempt.
+type FeedbackStrategy interface {
+	Strategy
+	// Observe Record the outcome of an attempt, err is nil on success.
+	Observe(gateway string, latency time.Duration, err error)
+}
+
+// RateLimitStore Store the rate limit state, taking must be atomic if the store is shared.
+type RateLimitStore interface {
+	// TakeToken Take a token from the token bucket of the key, it returns how long to wait if the bucket is empty.
+	TakeToken(key string, limit *RateLimit) (time.Duration, error)
+	// TakeWindow Record an event in the sliding windows of the key unless one of the limits is reached,
+	// it returns how long to wait otherwise.
+	TakeWindow(key string, limits []*WindowLimit) (time.Duration, error)
+}
+
+// ScheduleStore Store the sends scheduled by gsms until they are due.
+type ScheduleStore interface {
+	// Add Add a scheduled send.
+	Add(send *ScheduledSend) error
+	// Remove Remove a scheduled send, ErrScheduleNotFound if it is not in the store.
+	Remove(id string) error
+	// Due Remove and return the sends due at now.
+	Due(now time.Time) ([]*ScheduledSend, error)
+}
+
+// SuppressionStore Store the recipients that must not receive messages, e.g. numbers that replied STOP.
+type SuppressionStore interface {
+	// Add Suppress the recipient for the category of the suppression.
+	Add(suppression *Suppression) error
+	// Remove Remove the suppression of the recipient for the category.
+	Remove(to *PhoneNumber, category string) error
+	// Check Get the suppression of the recipient for the category, false if the recipient is not suppressed.
+	Check(to *PhoneNumber, category string) (*Suppression, bool, error)
+	// List List all suppressions.
+	List() ([]*Suppression, error)
+}
+
+// IdempotencyStore Store the results of the sends with an idempotency key.
+type IdempotencyStore interface {
+	// Get Get the results stored for the key, false if there are none or they expired.
+	Get(key string) ([]*Result, bool, error)
+	// Set Store the results for the key during ttl.
+	Set(key string, results []*Result, ttl time.Duration) error
+}
+
+// CostEstimator Estimate the cost of sending a message.
+type CostEstimator interface {
+	// EstimateCost Estimated cost of sending the message to the recipient via the gateway, false if unknown.
+	EstimateCost(gateway Gateway, to *PhoneNumber, message Message) (float64, bool)
+}
+
+// HealthTracker Track the health of gateways.
+type HealthTracker interface {
+	// Healthy Whether the gateway should be tried in its normal order, unhealthy gateways are tried last.
+	Healthy(gateway string) bool
+	// Success Record a successful send of the gateway.
+	Success(gateway string)
+	// Failure Record a failed send of the gateway.
+	Failure(gateway string)
+	// Health Get the health of the gateway.
+	Health(gateway string) GatewayHealth
+}
+
+// ProbingHealthTracker HealthTracker limiting the sends of unhealthy gateways, e.g. the probes of a half-open circuit.
+type ProbingHealthTracker interface {
+	HealthTracker
+	// Probe Reserve a send of the gateway, it is called right before the gateway is sent to.
+	Probe(gateway string)
+}
+
 // LogLevel log level
 type LogLevel int
 
@@ -29,8 +29,15 @@ type Message struct {
 	Template interface{}
 	Data     interface{}
 	Type     interface{}
+	// IdempotencyKey Sends with the same key are only sent once, see gsms.IdempotentMessage.
+	IdempotencyKey string
+	// Category Category of the message, e.g. gsms.CategoryOTP, see gsms.SendWindowPolicy.
+	Category string
 }
 
+var _ gsms.IdempotentMessage = (*Message)(nil)
+var _ gsms.CategorizedMessage = (*Message)(nil)
+
 // Gateways Supported gateways.
 func (m *Message) Gateways() ([]string, error) {
 	return nil, nil
@@ -76,6 +83,16 @@ func (m *Message) GetData(gateway gsms.Gateway) (map[string]string, error) {
 	return nil, nil
 }
 
+// GetIdempotencyKey Get the idempotency key.
+func (m *Message) GetIdempotencyKey() string {
+	return m.IdempotencyKey
+}
+
+// GetCategory Get the category.
+func (m *Message) GetCategory() string {
+	return m.Category
+}
+
 // GetType Get message type.
 func (m *Message) GetType(gateway gsms.Gateway) (string, error) {
 	switch messageType := m.Type.(type) {
@@ -0,0 +1,193 @@
+package message
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"reflect"
+	"sort"
+)
+
+var _ gsms.IdempotentMessage = (*Payload)(nil)
+var _ gsms.CategorizedMessage = (*Payload)(nil)
+
+// Payload Serializable message, the per gateway values of a message are evaluated into Overrides.
+// Strategies are not serializable, Payload uses the strategy of gsms.
+type Payload struct {
+	Content  string            `json:"content,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Data     map[string]string `json:"data,omitempty"`
+	Type     string            `json:"type,omitempty"`
+	// GatewayNames Supported gateways.
+	GatewayNames []string `json:"gateways,omitempty"`
+	// Overrides Values of a gateway that differ from the default ones, keyed by gateway name.
+	Overrides map[string]*Override `json:"overrides,omitempty"`
+	// IdempotencyKey Idempotency key of the message, see gsms.IdempotentMessage.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Category Category of the message, see gsms.CategorizedMessage.
+	Category string `json:"category,omitempty"`
+}
+
+// Override Values of a gateway, empty values fall back to the payload ones.
+type Override struct {
+	Content  string            `json:"content,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Data     map[string]string `json:"data,omitempty"`
+	Type     string            `json:"type,omitempty"`
+}
+
+// NewPayload Evaluate the message into a payload.
+// The default values are evaluated with a gateway without name, the values of message.Gateways() and gateways are
+// evaluated with their names, so message funcs switching on gateway.Name() are kept.
+func NewPayload(message gsms.Message, gateways ...string) (*Payload, error) {
+	supported, err := message.Gateways()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := evaluate(message, gsms.NamedGateway(""))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{
+		Content:      defaults.Content,
+		Template:     defaults.Template,
+		Data:         defaults.Data,
+		Type:         defaults.Type,
+		GatewayNames: supported,
+	}
+
+	if message, ok := message.(gsms.IdempotentMessage); ok {
+		payload.IdempotencyKey = message.GetIdempotencyKey()
+	}
+
+	if message, ok := message.(gsms.CategorizedMessage); ok {
+		payload.Category = message.GetCategory()
+	}
+
+	names := map[string]bool{}
+	for _, name := range append(append([]string{}, supported...), gateways...) {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		values, err := evaluate(message, gsms.NamedGateway(name))
+		if err != nil {
+			return nil, err
+		}
+
+		override := &Override{}
+		if values.Content != defaults.Content {
+			override.Content = values.Content
+		}
+		if values.Template != defaults.Template {
+			override.Template = values.Template
+		}
+		if !reflect.DeepEqual(values.Data, defaults.Data) {
+			override.Data = values.Data
+		}
+		if values.Type != defaults.Type {
+			override.Type = values.Type
+		}
+
+		if override.Content != "" || override.Template != "" || override.Data != nil || override.Type != "" {
+			if payload.Overrides == nil {
+				payload.Overrides = map[string]*Override{}
+			}
+			payload.Overrides[name] = override
+		}
+	}
+
+	return payload, nil
+}
+
+// Gateways Supported gateways.
+func (p *Payload) Gateways() ([]string, error) {
+	return p.GatewayNames, nil
+}
+
+// Strategy Message strategy.
+func (p *Payload) Strategy() (gsms.Strategy, error) {
+	return nil, nil
+}
+
+// GetContent Get message content.
+func (p *Payload) GetContent(gateway gsms.Gateway) (string, error) {
+	if override := p.override(gateway); override != nil && override.Content != "" {
+		return override.Content, nil
+	}
+
+	return p.Content, nil
+}
+
+// GetTemplate Get message template.
+func (p *Payload) GetTemplate(gateway gsms.Gateway) (string, error) {
+	if override := p.override(gateway); override != nil && override.Template != "" {
+		return override.Template, nil
+	}
+
+	return p.Template, nil
+}
+
+// GetData Get message data.
+func (p *Payload) GetData(gateway gsms.Gateway) (map[string]string, error) {
+	if override := p.override(gateway); override != nil && override.Data != nil {
+		return override.Data, nil
+	}
+
+	return p.Data, nil
+}
+
+// GetType Get message type.
+func (p *Payload) GetType(gateway gsms.Gateway) (string, error) {
+	if override := p.override(gateway); override != nil && override.Type != "" {
+		return override.Type, nil
+	}
+
+	return p.Type, nil
+}
+
+// GetIdempotencyKey Get the idempotency key.
+func (p *Payload) GetIdempotencyKey() string {
+	return p.IdempotencyKey
+}
+
+// GetCategory Get the category.
+func (p *Payload) GetCategory() string {
+	return p.Category
+}
+
+// override Get the override of the gateway.
+func (p *Payload) override(gateway gsms.Gateway) *Override {
+	if gateway == nil || p.Overrides == nil {
+		return nil
+	}
+
+	return p.Overrides[gateway.Name()]
+}
+
+// evaluate Evaluate the values of the message for the gateway.
+func evaluate(message gsms.Message, gateway gsms.Gateway) (*Override, error) {
+	var values Override
+	var err error
+
+	if values.Content, err = message.GetContent(gateway); err != nil {
+		return nil, err
+	}
+	if values.Template, err = message.GetTemplate(gateway); err != nil {
+		return nil, err
+	}
+	if values.Data, err = message.GetData(gateway); err != nil {
+		return nil, err
+	}
+	if values.Type, err = message.GetType(gateway); err != nil {
+		return nil, err
+	}
+
+	return &values, nil
+}
@@ -0,0 +1,80 @@
+package message
+
+import (
+	"encoding/json"
+	"github.com/maiqingqiang/gsms"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testMessage struct {
+	Message
+}
+
+func (t *testMessage) Gateways() ([]string, error) {
+	return []string{"aliyun"}, nil
+}
+
+func TestNewPayload(t *testing.T) {
+	msg := &testMessage{Message{
+		Template: func(gateway gsms.Gateway) string {
+			if gateway.Name() == "aliyun" {
+				return "SMS_271311117"
+			}
+			return "5532011"
+		},
+		Data: func(gateway gsms.Gateway) map[string]string {
+			if gateway.Name() == "qcloud" {
+				return map[string]string{"1": "9527"}
+			}
+			return map[string]string{"code": "9527"}
+		},
+		Type:           TextMessage,
+		IdempotencyKey: "order-1",
+	}}
+
+	payload, err := NewPayload(msg, "qcloud", "yunpian")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.JSONEq(t, `{
+		"template": "5532011",
+		"data": {"code": "9527"},
+		"type": "text",
+		"gateways": ["aliyun"],
+		"idempotency_key": "order-1",
+		"overrides": {
+			"aliyun": {"template": "SMS_271311117"},
+			"qcloud": {"data": {"1": "9527"}}
+		}
+	}`, string(data))
+
+	var decoded Payload
+	if !assert.NoError(t, json.Unmarshal(data, &decoded)) {
+		return
+	}
+
+	for _, name := range []string{"aliyun", "qcloud", "yunpian", "unknown"} {
+		gateway := gsms.NamedGateway(name)
+
+		want, _ := msg.GetTemplate(gateway)
+		got, _ := decoded.GetTemplate(gateway)
+		assert.Equal(t, want, got, name)
+
+		wantData, _ := msg.GetData(gateway)
+		gotData, _ := decoded.GetData(gateway)
+		assert.Equal(t, wantData, gotData, name)
+
+		gotType, _ := decoded.GetType(gateway)
+		assert.Equal(t, TextMessage, gotType, name)
+	}
+
+	gateways, _ := decoded.Gateways()
+	assert.Equal(t, []string{"aliyun"}, gateways)
+}
@@ -0,0 +1,155 @@
+// Package metrics Prometheus metrics of gsms, it is a separate module so gsms does not depend on the Prometheus client.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/prometheus/client_golang/prometheus"
+	"sync"
+)
+
+const (
+	// DefaultNamespace Namespace of the metrics.
+	DefaultNamespace = "gsms"
+	// StatusCancelled Status of attempts cancelled by gsms, e.g. the losers of SendModeRace.
+	StatusCancelled = "cancelled"
+)
+
+// DefaultBuckets Buckets of the attempt latency histogram in seconds.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// Metrics Prometheus collector of the attempts, failovers, exhausted sends and circuit states of gsms.
+type Metrics struct {
+	namespace   string
+	buckets     []float64
+	constLabels prometheus.Labels
+
+	attempts  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	failovers *prometheus.CounterVec
+	exhausted prometheus.Counter
+	circuit   *prometheus.Desc
+
+	mu      sync.Mutex
+	clients []*gsms.Gsms
+}
+
+// New a collector, register it with a prometheus.Registerer and instrument gsms with Instrument.
+func New(options ...Option) *Metrics {
+	m := &Metrics{
+		namespace: DefaultNamespace,
+		buckets:   DefaultBuckets,
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	m.attempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   m.namespace,
+		Name:        "attempts_total",
+		Help:        "Gateway attempts by gateway, status and error class.",
+		ConstLabels: m.constLabels,
+	}, []string{"gateway", "status", "error_class"})
+
+	m.latency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   m.namespace,
+		Name:        "attempt_duration_seconds",
+		Help:        "Latency of the gateway attempts.",
+		Buckets:     m.buckets,
+		ConstLabels: m.constLabels,
+	}, []string{"gateway", "status"})
+
+	m.failovers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   m.namespace,
+		Name:        "failovers_total",
+		Help:        "Failovers from a failed gateway to the next one.",
+		ConstLabels: m.constLabels,
+	}, []string{"from", "to"})
+
+	m.exhausted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   m.namespace,
+		Name:        "sends_exhausted_total",
+		Help:        "Sends that failed on every gateway.",
+		ConstLabels: m.constLabels,
+	})
+
+	m.circuit = prometheus.NewDesc(
+		prometheus.BuildFQName(m.namespace, "", "circuit_state"),
+		"Circuit state of the gateways, 0 closed, 1 open, 2 half-open.",
+		[]string{"gateway"},
+		m.constLabels,
+	)
+
+	return m
+}
+
+// Instrument Option recording the metrics of a gsms instance, e.g. gsms.New(gateways, m.Instrument()).
+// The circuit states are read from the first instrumented instance that has the gateway.
+func (m *Metrics) Instrument() func(*gsms.Gsms) {
+	return func(g *gsms.Gsms) {
+		m.mu.Lock()
+		m.clients = append(m.clients, g)
+		m.mu.Unlock()
+
+		g.OnAttemptSuccess(m.observeAttempt)
+		g.OnAttemptFailure(m.observeAttempt)
+		g.OnFailover(func(event *gsms.FailoverEvent) {
+			m.failovers.WithLabelValues(event.From, event.To).Inc()
+		})
+		g.OnSendExhausted(func(event *gsms.SendExhaustedEvent) {
+			m.exhausted.Inc()
+		})
+	}
+}
+
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.attempts.Describe(ch)
+	m.latency.Describe(ch)
+	m.failovers.Describe(ch)
+	m.exhausted.Describe(ch)
+	ch <- m.circuit
+}
+
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.attempts.Collect(ch)
+	m.latency.Collect(ch)
+	m.failovers.Collect(ch)
+	m.exhausted.Collect(ch)
+
+	m.mu.Lock()
+	clients := append([]*gsms.Gsms{}, m.clients...)
+	m.mu.Unlock()
+
+	seen := map[string]bool{}
+
+	for _, client := range clients {
+		for _, health := range client.GatewayHealth() {
+			if seen[health.Gateway] {
+				continue
+			}
+			seen[health.Gateway] = true
+
+			ch <- prometheus.MustNewConstMetric(m.circuit, prometheus.GaugeValue, float64(health.State), health.Gateway)
+		}
+	}
+}
+
+// observeAttempt Record the outcome of an attempt, the latency of cancelled attempts is not observed.
+func (m *Metrics) observeAttempt(event *gsms.AttemptEvent) {
+	if errors.Is(event.Error, context.Canceled) {
+		m.attempts.WithLabelValues(event.Gateway, StatusCancelled, "").Inc()
+		return
+	}
+
+	status, errorClass := gsms.StatusSuccess, ""
+	if event.Error != nil {
+		status, errorClass = gsms.StatusFailure, gsms.ClassifyError(event.Error).String()
+	}
+
+	m.attempts.WithLabelValues(event.Gateway, status, errorClass).Inc()
+	m.latency.WithLabelValues(event.Gateway, status).Observe(event.Duration.Seconds())
+}
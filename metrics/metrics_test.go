@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testGateway struct {
+	name string
+	err  error
+}
+
+func (t *testGateway) Name() string {
+	return t.name
+}
+
+func (t *testGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return t.err
+}
+
+func TestMetrics(t *testing.T) {
+	m := New(WithNamespace("sms"))
+
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(m))
+
+	client := gsms.New([]gsms.Gateway{
+		&testGateway{name: "a", err: gsms.NewSendError("a", gsms.ErrorCodeInsufficientBalance, "", "")},
+		&testGateway{name: "b"},
+	},
+		gsms.WithGateways([]string{"a", "b"}),
+		gsms.WithHealthTracker(gsms.NewCircuitBreaker(1, time.Minute, 1)),
+		m.Instrument(),
+	)
+
+	_, err := client.Send(18888888888, &message.Message{Template: "SMS_00000001"})
+	assert.NoError(t, err)
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP sms_attempts_total Gateway attempts by gateway, status and error class.
+# TYPE sms_attempts_total counter
+sms_attempts_total{error_class="insufficient_balance",gateway="a",status="failure"} 1
+sms_attempts_total{error_class="",gateway="b",status="success"} 1
+# HELP sms_circuit_state Circuit state of the gateways, 0 closed, 1 open, 2 half-open.
+# TYPE sms_circuit_state gauge
+sms_circuit_state{gateway="a"} 1
+sms_circuit_state{gateway="b"} 0
+# HELP sms_failovers_total Failovers from a failed gateway to the next one.
+# TYPE sms_failovers_total counter
+sms_failovers_total{from="a",to="b"} 1
+`), "sms_attempts_total", "sms_circuit_state", "sms_failovers_total")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m, "sms_attempt_duration_seconds"))
+	assert.Zero(t, testutil.ToFloat64(m.exhausted))
+
+	client = gsms.New([]gsms.Gateway{&testGateway{name: "c", err: errors.New("timeout")}}, gsms.WithGateways([]string{"c"}), m.Instrument())
+
+	_, err = client.Send(18888888888, &message.Message{Template: "SMS_00000001"})
+	assert.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.exhausted))
+}
+
+type slowGateway struct {
+	name string
+}
+
+func (s *slowGateway) Name() string {
+	return s.name
+}
+
+func (s *slowGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return s.SendContext(context.Background(), to, message, config)
+}
+
+func (s *slowGateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	select {
+	case <-time.After(time.Second):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestMetrics_Race(t *testing.T) {
+	m := New()
+
+	client := gsms.New([]gsms.Gateway{&slowGateway{name: "a"}, &testGateway{name: "b"}},
+		gsms.WithGateways([]string{"a", "b"}),
+		gsms.WithSendMode(gsms.SendModeRace),
+		m.Instrument(),
+	)
+
+	_, err := client.Send(18888888888, &message.Message{Template: "SMS_00000001"})
+	assert.NoError(t, err)
+
+	// the loser of the race is neither a failure nor observed in the latency histogram
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.attempts.WithLabelValues("a", StatusCancelled, "")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.attempts.WithLabelValues("b", gsms.StatusSuccess, "")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.attempts, "gsms_attempts_total")-1)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.latency))
+}
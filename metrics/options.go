@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Option func(*Metrics)
+
+// WithNamespace set the namespace of the metrics, it defaults to DefaultNamespace.
+func WithNamespace(namespace string) func(*Metrics) {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithBuckets set the buckets of the attempt latency histogram in seconds, it defaults to DefaultBuckets.
+func WithBuckets(buckets []float64) func(*Metrics) {
+	return func(m *Metrics) {
+		m.buckets = buckets
+	}
+}
+
+// WithConstLabels set labels added to every metric, e.g. the service name.
+func WithConstLabels(labels prometheus.Labels) func(*Metrics) {
+	return func(m *Metrics) {
+		m.constLabels = labels
+	}
+}
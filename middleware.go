@@ -0,0 +1,97 @@
+package gsms
+
+import (
+	"context"
+	"net/http"
+)
+
+// SendFunc Send a short message via the gateway once and return the provider receipt, nil if there is none.
+type SendFunc func(ctx context.Context, gateway Gateway, to *PhoneNumber, message Message, config *Config) (*Receipt, error)
+
+// Middleware Wrap a SendFunc, e.g. to log, rewrite the message or intercept sends in tests.
+type Middleware func(next SendFunc) SendFunc
+
+// BatchSendFunc Send a short message to multiple recipients via the gateway once, the statuses are in the same order as to.
+type BatchSendFunc func(ctx context.Context, gateway BatchGateway, to []*PhoneNumber, message Message, config *Config) ([]*BatchStatus, error)
+
+// BatchMiddleware Wrap a BatchSendFunc, it is the Middleware of the requests of BatchGateway.
+type BatchMiddleware func(next BatchSendFunc) BatchSendFunc
+
+// SendRequest A send before routing, hooks can modify it.
+type SendRequest struct {
+	To      *PhoneNumber
+	Message Message
+	// Gateways Requested gateways, empty to use the ones of the message or gsms.
+	Gateways []string
+}
+
+// BeforeSendHook Called before routing, returning an error vetoes the send and the error is returned by Send.
+type BeforeSendHook func(ctx context.Context, request *SendRequest) error
+
+// AfterSendHook Called after all attempts with the outcome of the send, including vetoed ones.
+type AfterSendHook func(ctx context.Context, request *SendRequest, results []*Result, err error)
+
+// SendInterceptor Wrap a send including the hooks and all attempts, e.g. to trace it.
+// It must call send, the send uses the context passed to it.
+type SendInterceptor func(ctx context.Context, request *SendRequest, send func(ctx context.Context) ([]*Result, error)) ([]*Result, error)
+
+// RequestHook Called by the gateways before an HTTP request is sent to the provider, e.g. to trace it.
+// It returns the request to send and a function called with the status code, 0 without a response, and the error of the request.
+type RequestHook func(req *http.Request) (*http.Request, func(statusCode int, err error))
+
+// chain Wrap the send with the middlewares, the first middleware is the outermost.
+func (g *Gsms) chain(send SendFunc) SendFunc {
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		send = g.middlewares[i](send)
+	}
+
+	return send
+}
+
+// chainBatch Wrap the batch send with the batch middlewares, the first middleware is the outermost.
+func (g *Gsms) chainBatch(send BatchSendFunc) BatchSendFunc {
+	for i := len(g.batchMiddlewares) - 1; i >= 0; i-- {
+		send = g.batchMiddlewares[i](send)
+	}
+
+	return send
+}
+
+// sendBatch Innermost send of a batch request.
+func sendBatch(ctx context.Context, gateway BatchGateway, to []*PhoneNumber, message Message, config *Config) ([]*BatchStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return gateway.SendBatch(ctx, to, message, config)
+}
+
+// intercept Wrap the send with the interceptors, the first interceptor is the outermost.
+func (g *Gsms) intercept(request *SendRequest, send func(ctx context.Context) ([]*Result, error)) func(ctx context.Context) ([]*Result, error) {
+	for i := len(g.sendInterceptors) - 1; i >= 0; i-- {
+		interceptor, next := g.sendInterceptors[i], send
+		send = func(ctx context.Context) ([]*Result, error) {
+			return interceptor(ctx, request, next)
+		}
+	}
+
+	return send
+}
+
+// beforeSend Run the before send hooks until one of them vetoes the send.
+func (g *Gsms) beforeSend(ctx context.Context, request *SendRequest) error {
+	for _, hook := range g.beforeSendHooks {
+		if err := hook(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// afterSend Run the after send hooks.
+func (g *Gsms) afterSend(ctx context.Context, request *SendRequest, results []*Result, err error) {
+	for _, hook := range g.afterSendHooks {
+		hook(ctx, request, results, err)
+	}
+}
@@ -0,0 +1,227 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGsms_Send_Middleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var calls []string
+
+	trace := func(name string) Middleware {
+		return func(next SendFunc) SendFunc {
+			return func(ctx context.Context, gateway Gateway, to *PhoneNumber, message Message, config *Config) (*Receipt, error) {
+				calls = append(calls, name+":"+gateway.Name())
+				return next(ctx, gateway, to, message, config)
+			}
+		}
+	}
+
+	intercept := func(next SendFunc) SendFunc {
+		return func(ctx context.Context, gateway Gateway, to *PhoneNumber, message Message, config *Config) (*Receipt, error) {
+			if gateway.Name() == "a" {
+				return nil, errors.New("intercepted")
+			}
+			return &Receipt{MessageID: "1001"}, nil
+		}
+	}
+
+	g := New([]Gateway{&flakyGateway{name: "a"}, &flakyGateway{name: "b"}},
+		WithGateways([]string{"a", "b"}),
+		WithMiddleware(trace("outer"), trace("inner")),
+		WithMiddleware(intercept),
+	)
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"outer:a", "inner:a", "outer:b", "inner:b"}, calls)
+
+	if assert.Len(t, results, 2) {
+		assert.EqualError(t, errors.Unwrap(results[0].Error), "intercepted")
+		assert.Equal(t, "1001", results[1].Receipt.MessageID)
+	}
+}
+
+func TestGsms_Send_Hooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a := &flakyGateway{name: "a"}
+	b := &flakyGateway{name: "b"}
+
+	var outcomes []error
+
+	veto := errors.New("blocked by audit")
+
+	g := New([]Gateway{a, b},
+		WithGateways([]string{"a"}),
+		WithBeforeSend(func(ctx context.Context, request *SendRequest) error {
+			if request.To.Number() == 18888888889 {
+				return veto
+			}
+
+			request.Gateways = []string{"b"}
+			return nil
+		}),
+		WithAfterSend(func(ctx context.Context, request *SendRequest, results []*Result, err error) {
+			outcomes = append(outcomes, err)
+
+			if err == nil {
+				assert.Equal(t, []string{"b"}, request.Gateways)
+				assert.Equal(t, "b", results[0].Gateway)
+			}
+		}),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	_, err = g.Send(18888888889, newAnyMessage(ctrl))
+	assert.ErrorIs(t, err, veto)
+
+	assert.Equal(t, []error{nil, veto}, outcomes)
+	assert.Zero(t, a.calls)
+	assert.Equal(t, int32(1), b.calls)
+}
+
+func TestGsms_Send_SendInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type key struct{}
+
+	var calls []string
+
+	trace := func(name string) SendInterceptor {
+		return func(ctx context.Context, request *SendRequest, send func(ctx context.Context) ([]*Result, error)) ([]*Result, error) {
+			calls = append(calls, name)
+
+			results, err := send(context.WithValue(ctx, key{}, name))
+
+			calls = append(calls, name+":"+results[0].Gateway)
+			return results, err
+		}
+	}
+
+	hook := func(req *http.Request) (*http.Request, func(statusCode int, err error)) {
+		return req, func(statusCode int, err error) {}
+	}
+
+	g := New([]Gateway{&flakyGateway{name: "a"}},
+		WithGateways([]string{"a"}),
+		WithSendInterceptor(trace("outer"), trace("inner")),
+		WithRequestHook(hook),
+		WithAfterSend(func(ctx context.Context, request *SendRequest, results []*Result, err error) {
+			calls = append(calls, "after:"+ctx.Value(key{}).(string))
+		}),
+		WithMiddleware(func(next SendFunc) SendFunc {
+			return func(ctx context.Context, gateway Gateway, to *PhoneNumber, message Message, config *Config) (*Receipt, error) {
+				calls = append(calls, "attempt:"+ctx.Value(key{}).(string))
+				assert.NotNil(t, config.RequestHook)
+				return next(ctx, gateway, to, message, config)
+			}
+		}),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"outer", "inner", "attempt:inner", "after:inner", "inner:a", "outer:a"}, calls)
+}
+
+func TestGsms_SendBatch_Middleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &testBatchGateway{size: 2}
+
+	var chunks []int
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"batch"}),
+		WithTimeout(time.Minute),
+		WithBatchMiddleware(func(next BatchSendFunc) BatchSendFunc {
+			return func(ctx context.Context, gateway BatchGateway, to []*PhoneNumber, message Message, config *Config) ([]*BatchStatus, error) {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+
+				chunks = append(chunks, len(to))
+				return next(ctx, gateway, to, message, config)
+			}
+		}),
+	)
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+		NewPhoneNumber(18800000003, "86"),
+	}
+
+	_, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, chunks)
+}
+
+func TestGsms_SendBatch_Hooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &testBatchGateway{size: 10, failure: map[int]bool{18800000003: true}}
+
+	veto := errors.New("blocked by audit")
+	outcomes := map[int]error{}
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"batch"}),
+		WithBeforeSend(func(ctx context.Context, request *SendRequest) error {
+			switch request.To.Number() {
+			case 18800000001:
+				return veto
+			case 18800000002:
+				request.To = NewPhoneNumber(18800000004, "86")
+			}
+			return nil
+		}),
+		WithAfterSend(func(ctx context.Context, request *SendRequest, results []*Result, err error) {
+			outcomes[request.To.Number()] = err
+		}),
+	)
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+		NewPhoneNumber(18800000003, "86"),
+	}
+
+	batch, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+
+	var batchErr *ErrBatchFailed
+	if !assert.ErrorAs(t, err, &batchErr) {
+		return
+	}
+
+	if assert.Len(t, gateway.chunks, 1) {
+		assert.Equal(t, []*PhoneNumber{NewPhoneNumber(18800000004, "86"), NewPhoneNumber(18800000003, "86")}, gateway.chunks[0])
+	}
+
+	assert.Equal(t, 18800000004, batch[1].To.Number())
+
+	if assert.Len(t, outcomes, 3) {
+		assert.ErrorIs(t, outcomes[18800000001], veto)
+		assert.NoError(t, outcomes[18800000004])
+
+		var failed *ErrGatewaysFailed
+		assert.ErrorAs(t, outcomes[18800000003], &failed)
+	}
+}
@@ -26,3 +26,168 @@ func WithStrategy(strategy Strategy) func(*Gsms) {
 		gsms.strategy = strategy
 	}
 }
+
+// WithSendMode set the send mode.
+func WithSendMode(mode SendMode) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.sendMode = mode
+	}
+}
+
+// WithHedgeDelay set the delay before the next gateway is started in SendModeHedged.
+func WithHedgeDelay(delay time.Duration) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.hedgeDelay = delay
+	}
+}
+
+// WithRetryPolicy set the retry policy of all gateways.
+func WithRetryPolicy(policy *RetryPolicy) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.defaultRetryPolicy = policy
+	}
+}
+
+// WithGatewayRetryPolicy set the retry policy of the gateway, it overrides WithRetryPolicy.
+func WithGatewayRetryPolicy(gateway string, policy *RetryPolicy) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.gatewayRetryPolicies[gateway] = policy
+	}
+}
+
+// WithRateLimitStore set the store of the rate limits, it defaults to an in-memory store.
+func WithRateLimitStore(store RateLimitStore) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.rateLimitStore = store
+	}
+}
+
+// WithGatewayRateLimit set the token bucket limit of the gateway, requests over the limit fail over to the next gateway.
+func WithGatewayRateLimit(gateway string, limit *RateLimit) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.gatewayRateLimits[gateway] = limit
+	}
+}
+
+// WithRecipientRateLimit set the sliding window limits of every recipient, e.g. 1 per minute and 10 per day.
+func WithRecipientRateLimit(limits ...*WindowLimit) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.recipientRateLimits = limits
+	}
+}
+
+// WithIdempotencyStore set the store of the idempotency keys, it defaults to an in-memory store.
+func WithIdempotencyStore(store IdempotencyStore) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.idempotencyStore = store
+	}
+}
+
+// WithIdempotencyWindow set how long the results of a send with an idempotency key are returned again,
+// it defaults to DefaultIdempotencyWindow.
+func WithIdempotencyWindow(window time.Duration) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.idempotencyWindow = window
+	}
+}
+
+// WithScheduleStore set the store of the scheduled sends, it defaults to an in-memory timer wheel.
+func WithScheduleStore(store ScheduleStore) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.scheduler.store = store
+	}
+}
+
+// WithScheduleInterval set how often the schedule store is polled for due sends, it defaults to DefaultScheduleTick.
+func WithScheduleInterval(interval time.Duration) func(*Gsms) {
+	if interval <= 0 {
+		interval = DefaultScheduleTick
+	}
+
+	return func(gsms *Gsms) {
+		gsms.scheduler.interval = interval
+	}
+}
+
+// WithScheduleCallback set the callback called with the outcome of every send scheduled by gsms.
+func WithScheduleCallback(callback func(send *ScheduledSend, results []*Result, err error)) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.scheduler.callback = callback
+	}
+}
+
+// WithSendWindowPolicy set the send windows of the message categories, messages outside their window are rejected
+// with ErrOutsideSendWindow or deferred to the next window.
+func WithSendWindowPolicy(policy *SendWindowPolicy) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.sendWindowPolicy = policy
+	}
+}
+
+// WithSuppressionStore set the suppression list, suppressed recipients fail with ErrRecipientSuppressed before any
+// gateway is tried.
+func WithSuppressionStore(store SuppressionStore) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.suppressionStore = store
+	}
+}
+
+// WithMiddleware add middlewares wrapping every gateway attempt, the first one is the outermost.
+// Requests of BatchGateway sending to several recipients are wrapped by the batch middlewares instead.
+func WithMiddleware(middlewares ...Middleware) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.middlewares = append(gsms.middlewares, middlewares...)
+	}
+}
+
+// WithBatchMiddleware add middlewares wrapping every request of BatchGateway, the first one is the outermost.
+func WithBatchMiddleware(middlewares ...BatchMiddleware) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.batchMiddlewares = append(gsms.batchMiddlewares, middlewares...)
+	}
+}
+
+// WithBeforeSend add hooks called before routing a send, they can modify or veto it.
+// Batch sends call them for each recipient, only changes of the recipient are applied.
+func WithBeforeSend(hooks ...BeforeSendHook) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.beforeSendHooks = append(gsms.beforeSendHooks, hooks...)
+	}
+}
+
+// WithAfterSend add hooks called with the outcome of a send after all attempts.
+func WithAfterSend(hooks ...AfterSendHook) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.afterSendHooks = append(gsms.afterSendHooks, hooks...)
+	}
+}
+
+// WithSendInterceptor add interceptors wrapping every send including the hooks, the first one is the outermost.
+// Batch sends are not wrapped.
+func WithSendInterceptor(interceptors ...SendInterceptor) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.sendInterceptors = append(gsms.sendInterceptors, interceptors...)
+	}
+}
+
+// WithRequestHook set the hook of the HTTP requests the gateways send to the providers.
+func WithRequestHook(hook RequestHook) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.config.RequestHook = hook
+	}
+}
+
+// WithCostEstimator set the cost estimator, the estimated cost is reported in Result.Cost.
+// A strategy implementing CostEstimator is used if it is not set.
+func WithCostEstimator(estimator CostEstimator) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.costEstimator = estimator
+	}
+}
+
+// WithHealthTracker set the health tracker, unhealthy gateways are tried last.
+func WithHealthTracker(tracker HealthTracker) func(*Gsms) {
+	return func(gsms *Gsms) {
+		gsms.healthTracker = tracker
+	}
+}
@@ -0,0 +1,30 @@
+package otp
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrExpired         = errors.New("verification code expired or not sent")
+	ErrMismatched      = errors.New("verification code mismatched")
+	ErrTooManyAttempts = errors.New("too many verification attempts")
+	ErrInvalidCharset  = errors.New("verification code charset must not be empty")
+	ErrInvalidLength   = errors.New("verification code length must be positive")
+)
+
+// ErrCooldown A code was sent to the phone number recently.
+type ErrCooldown struct {
+	// RetryAfter How long to wait before sending again.
+	RetryAfter time.Duration
+}
+
+// NewErrCooldown New a cooldown error.
+func NewErrCooldown(retryAfter time.Duration) *ErrCooldown {
+	return &ErrCooldown{RetryAfter: retryAfter}
+}
+
+func (e *ErrCooldown) Error() string {
+	return fmt.Sprintf("verification code sent recently, retry after %s", e.RetryAfter)
+}
@@ -0,0 +1,79 @@
+package otp
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"time"
+)
+
+type Option func(*OTP)
+
+// WithStore set the store, it defaults to an in-memory store.
+func WithStore(store Store) func(*OTP) {
+	return func(o *OTP) {
+		o.store = store
+	}
+}
+
+// WithLength set the code length, sending fails with ErrInvalidLength if it is not positive.
+func WithLength(length int) func(*OTP) {
+	return func(o *OTP) {
+		o.length = length
+	}
+}
+
+// WithCharset set the code charset, e.g. DigitCharset or AlphanumericCharset.
+func WithCharset(charset string) func(*OTP) {
+	return func(o *OTP) {
+		o.charset = []rune(charset)
+	}
+}
+
+// WithTTL set how long a code is valid.
+func WithTTL(ttl time.Duration) func(*OTP) {
+	return func(o *OTP) {
+		o.ttl = ttl
+	}
+}
+
+// WithCooldown set the minimum interval between two codes sent to the same phone number, 0 disables it.
+func WithCooldown(cooldown time.Duration) func(*OTP) {
+	return func(o *OTP) {
+		o.cooldown = cooldown
+	}
+}
+
+// WithMaxAttempts set the maximum verify attempts of a code.
+func WithMaxAttempts(maxAttempts int) func(*OTP) {
+	return func(o *OTP) {
+		o.maxAttempts = maxAttempts
+	}
+}
+
+// WithSecret set the HMAC secret of the stored hashes, recommended if the store is shared.
+func WithSecret(secret []byte) func(*OTP) {
+	return func(o *OTP) {
+		o.secret = secret
+	}
+}
+
+// WithTemplate set the message template, a string or a func(gateway gsms.Gateway) string.
+func WithTemplate(template interface{}) func(*OTP) {
+	return func(o *OTP) {
+		o.template = template
+	}
+}
+
+// WithDataKey set the template data key of the code, it defaults to "code".
+func WithDataKey(dataKey string) func(*OTP) {
+	return func(o *OTP) {
+		o.dataKey = dataKey
+	}
+}
+
+// WithMessage set the message builder, it overrides WithTemplate and WithDataKey.
+// The message should have the gsms.CategoryOTP category to bypass the send windows.
+func WithMessage(message func(code string) gsms.Message) func(*OTP) {
+	return func(o *OTP) {
+		o.message = message
+	}
+}
@@ -0,0 +1,224 @@
+package otp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"math/big"
+	"time"
+)
+
+const (
+	// DigitCharset Digits.
+	DigitCharset = "0123456789"
+	// AlphanumericCharset Upper case letters and digits without the easily confused 0, 1, I and O.
+	AlphanumericCharset = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+const (
+	DefaultLength      = 6
+	DefaultTTL         = 5 * time.Minute
+	DefaultCooldown    = time.Minute
+	DefaultMaxAttempts = 5
+	DefaultDataKey     = "code"
+)
+
+// OTP Send and verify one-time verification codes through gsms.
+type OTP struct {
+	client *gsms.Gsms
+	store  Store
+
+	length      int
+	charset     []rune
+	ttl         time.Duration
+	cooldown    time.Duration
+	maxAttempts int
+	secret      []byte
+
+	template interface{}
+	dataKey  string
+	message  func(code string) gsms.Message
+
+	now func() time.Time
+}
+
+// New an OTP instance sending codes with the client.
+func New(client *gsms.Gsms, options ...Option) *OTP {
+	o := &OTP{
+		client:      client,
+		store:       NewMemoryStore(),
+		length:      DefaultLength,
+		charset:     []rune(DigitCharset),
+		ttl:         DefaultTTL,
+		cooldown:    DefaultCooldown,
+		maxAttempts: DefaultMaxAttempts,
+		dataKey:     DefaultDataKey,
+		now:         time.Now,
+	}
+
+	for _, option := range options {
+		option(o)
+	}
+
+	return o
+}
+
+// Send a new verification code to the phone number, the previous code is replaced.
+func (o *OTP) Send(to *gsms.PhoneNumber) ([]*gsms.Result, error) {
+	return o.SendContext(context.Background(), to)
+}
+
+// SendContext Send a new verification code to the phone number with context.
+// It returns ErrCooldown if a code was sent within the cooldown, concurrent sends to the same phone number are
+// subject to the cooldown too. If sending fails the new code is discarded and the previous one is restored.
+func (o *OTP) SendContext(ctx context.Context, to *gsms.PhoneNumber) ([]*gsms.Result, error) {
+	key := o.key(to)
+	now := o.now()
+
+	code, err := o.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{Hash: o.hash(to, code), SentAt: now}
+
+	var previous *Entry
+
+	for {
+		previous, err = o.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if previous != nil && o.cooldown > 0 {
+			if wait := previous.SentAt.Add(o.cooldown).Sub(now); wait > 0 {
+				return nil, NewErrCooldown(wait)
+			}
+		}
+
+		swapped, err := o.store.CompareAndSwap(key, previous, entry, o.ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		if swapped {
+			break
+		}
+	}
+
+	results, err := o.client.SendContext(ctx, to, o.buildMessage(code))
+	if err != nil {
+		o.restore(key, entry, previous, now)
+		return results, err
+	}
+
+	return results, nil
+}
+
+// restore Restore the previous entry of a code that failed to send, unless another code was sent meanwhile.
+func (o *OTP) restore(key string, entry, previous *Entry, now time.Time) {
+	var ttl time.Duration
+	if previous != nil {
+		ttl = previous.SentAt.Add(o.ttl).Sub(now)
+	}
+
+	if ttl <= 0 {
+		previous = nil
+	}
+
+	_, _ = o.store.CompareAndSwap(key, entry, previous, ttl)
+}
+
+// Verify Verify the code sent to the phone number, a code can be verified successfully only once.
+// It returns ErrExpired, ErrMismatched or ErrTooManyAttempts if the code is not valid.
+func (o *OTP) Verify(to *gsms.PhoneNumber, code string) error {
+	key := o.key(to)
+
+	// The code is compared with the entry whose attempts were increased, a code sent meanwhile is never compared
+	// with the previous hash.
+	entry, err := o.store.IncrAttempts(key)
+	if err != nil {
+		return err
+	}
+
+	if entry == nil {
+		return ErrExpired
+	}
+
+	if entry.Attempts > o.maxAttempts {
+		return ErrTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare(o.hash(to, code), entry.Hash) != 1 {
+		return ErrMismatched
+	}
+
+	// Only the verified code is deleted, not a code sent meanwhile.
+	swapped, err := o.store.CompareAndSwap(key, entry, nil, 0)
+	if err != nil {
+		return err
+	}
+
+	if !swapped {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// generate Generate a random code.
+func (o *OTP) generate() (string, error) {
+	if len(o.charset) == 0 {
+		return "", ErrInvalidCharset
+	}
+
+	if o.length <= 0 {
+		return "", ErrInvalidLength
+	}
+
+	max := big.NewInt(int64(len(o.charset)))
+	code := make([]rune, o.length)
+
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = o.charset[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// hash HMAC-SHA256 of the phone number and code, so a hash can not be reused for another phone number.
+func (o *OTP) hash(to *gsms.PhoneNumber, code string) []byte {
+	h := hmac.New(sha256.New, o.secret)
+	h.Write([]byte(to.UniversalNumber()))
+	h.Write([]byte{0})
+	h.Write([]byte(code))
+	return h.Sum(nil)
+}
+
+// buildMessage Build the message of the code.
+func (o *OTP) buildMessage(code string) gsms.Message {
+	if o.message != nil {
+		return o.message(code)
+	}
+
+	return &message.Message{
+		Template: o.template,
+		Data: map[string]string{
+			o.dataKey: code,
+		},
+		Category: gsms.CategoryOTP,
+	}
+}
+
+// key Store key of the phone number.
+func (o *OTP) key(to *gsms.PhoneNumber) string {
+	return "gsms:otp:" + to.UniversalNumber()
+}
@@ -0,0 +1,297 @@
+package otp
+
+import (
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type codeGateway struct {
+	codes  []string
+	failed bool
+}
+
+func (c *codeGateway) Name() string {
+	return "code"
+}
+
+func (c *codeGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	if c.failed {
+		return errors.New("send failed")
+	}
+
+	data, err := message.GetData(c)
+	if err != nil {
+		return err
+	}
+
+	c.codes = append(c.codes, data[DefaultDataKey])
+	return nil
+}
+
+func newTestOTP(gateway *codeGateway, options ...Option) *OTP {
+	client := gsms.New([]gsms.Gateway{gateway}, gsms.WithGateways([]string{"code"}))
+	return New(client, append([]Option{WithTemplate("SMS_00000001")}, options...)...)
+}
+
+func TestOTP_Verify(t *testing.T) {
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway)
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	if !assert.NoError(t, err) || !assert.Len(t, gateway.codes, 1) {
+		return
+	}
+
+	code := gateway.codes[0]
+	assert.Len(t, code, DefaultLength)
+	assert.Equal(t, "", strings.Trim(code, DigitCharset))
+
+	assert.ErrorIs(t, o.Verify(gsms.NewPhoneNumber(18800000000, "86"), code), ErrExpired)
+	assert.ErrorIs(t, o.Verify(to, "abcdef"), ErrMismatched)
+	assert.NoError(t, o.Verify(to, code))
+	assert.ErrorIs(t, o.Verify(to, code), ErrExpired)
+}
+
+func TestOTP_Verify_Expired(t *testing.T) {
+	now := time.Now()
+
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway)
+
+	store := o.store.(*MemoryStore)
+	store.now = func() time.Time { return now }
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now = now.Add(DefaultTTL)
+
+	assert.ErrorIs(t, o.Verify(to, gateway.codes[0]), ErrExpired)
+}
+
+func TestOTP_Verify_TooManyAttempts(t *testing.T) {
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway, WithMaxAttempts(2))
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.ErrorIs(t, o.Verify(to, "wrong1"), ErrMismatched)
+	assert.ErrorIs(t, o.Verify(to, "wrong2"), ErrMismatched)
+	assert.ErrorIs(t, o.Verify(to, gateway.codes[0]), ErrTooManyAttempts)
+}
+
+func TestOTP_Send_Cooldown(t *testing.T) {
+	now := time.Now()
+
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway, WithCooldown(time.Minute))
+	o.now = func() time.Time { return now }
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	assert.NoError(t, err)
+
+	now = now.Add(20 * time.Second)
+
+	_, err = o.Send(to)
+
+	var cooldown *ErrCooldown
+	if assert.ErrorAs(t, err, &cooldown) {
+		assert.Equal(t, 40*time.Second, cooldown.RetryAfter)
+	}
+
+	now = now.Add(40 * time.Second)
+
+	_, err = o.Send(to)
+	if assert.NoError(t, err) && assert.Len(t, gateway.codes, 2) {
+		if gateway.codes[0] != gateway.codes[1] {
+			assert.ErrorIs(t, o.Verify(to, gateway.codes[0]), ErrMismatched)
+		}
+		assert.NoError(t, o.Verify(to, gateway.codes[1]))
+	}
+}
+
+func TestOTP_Send_Failed(t *testing.T) {
+	gateway := &codeGateway{failed: true}
+	o := newTestOTP(gateway)
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	assert.Error(t, err)
+
+	gateway.failed = false
+
+	_, err = o.Send(to)
+	assert.NoError(t, err)
+}
+
+func TestOTP_generate(t *testing.T) {
+	o := New(nil, WithLength(8), WithCharset(AlphanumericCharset))
+
+	code, err := o.generate()
+	if assert.NoError(t, err) {
+		assert.Len(t, code, 8)
+		assert.Equal(t, "", strings.Trim(code, AlphanumericCharset))
+	}
+
+	o = New(nil, WithCharset(""))
+
+	_, err = o.generate()
+	assert.ErrorIs(t, err, ErrInvalidCharset)
+
+	for _, length := range []int{0, -1} {
+		o = New(nil, WithLength(length))
+
+		_, err = o.generate()
+		assert.ErrorIs(t, err, ErrInvalidLength)
+	}
+}
+
+func TestOTP_Send_InvalidLength(t *testing.T) {
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway, WithLength(0))
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+	assert.Empty(t, gateway.codes)
+	assert.ErrorIs(t, o.Verify(to, ""), ErrExpired)
+}
+
+func TestOTP_hash(t *testing.T) {
+	o := New(nil, WithSecret([]byte("secret")))
+
+	a := gsms.NewPhoneNumber(18888888888, "86")
+	b := gsms.NewPhoneNumber(18800000000, "86")
+
+	assert.Equal(t, o.hash(a, "123456"), o.hash(a, "123456"))
+	assert.NotEqual(t, o.hash(a, "123456"), o.hash(b, "123456"))
+	assert.NotEqual(t, o.hash(a, "123456"), New(nil).hash(a, "123456"))
+}
+
+func TestOTP_Send_Concurrent(t *testing.T) {
+	gateway := &blockingGateway{release: make(chan struct{})}
+	client := gsms.New([]gsms.Gateway{gateway}, gsms.WithGateways([]string{"blocking"}))
+	o := New(client, WithTemplate("SMS_00000001"))
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := o.Send(to)
+			errs <- err
+		}()
+	}
+
+	// One of the sends is rejected by the cooldown before the other one is released.
+	var cooldown *ErrCooldown
+	assert.ErrorAs(t, <-errs, &cooldown)
+
+	close(gateway.release)
+	assert.NoError(t, <-errs)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&gateway.calls))
+}
+
+func TestOTP_Send_Failed_Restore(t *testing.T) {
+	now := time.Now()
+
+	gateway := &codeGateway{}
+	o := newTestOTP(gateway, WithCooldown(time.Minute))
+	o.now = func() time.Time { return now }
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	if !assert.NoError(t, err) || !assert.Len(t, gateway.codes, 1) {
+		return
+	}
+
+	now = now.Add(time.Minute)
+	gateway.failed = true
+
+	_, err = o.Send(to)
+	assert.Error(t, err)
+
+	// The previous code is restored, its cooldown is still over.
+	_, err = o.Send(to)
+
+	var cooldown *ErrCooldown
+	assert.False(t, errors.As(err, &cooldown))
+
+	assert.NoError(t, o.Verify(to, gateway.codes[0]))
+}
+
+type blockingGateway struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingGateway) Name() string {
+	return "blocking"
+}
+
+func (b *blockingGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	atomic.AddInt32(&b.calls, 1)
+	<-b.release
+	return nil
+}
+
+// resendStore Store sending a new code right before the attempts of the key are increased.
+type resendStore struct {
+	Store
+	resend func()
+}
+
+func (r *resendStore) IncrAttempts(key string) (*Entry, error) {
+	if r.resend != nil {
+		r.resend()
+		r.resend = nil
+	}
+
+	return r.Store.IncrAttempts(key)
+}
+
+func TestOTP_Verify_Resent(t *testing.T) {
+	gateway := &codeGateway{}
+	store := &resendStore{Store: NewMemoryStore()}
+	o := newTestOTP(gateway, WithStore(store), WithCooldown(0))
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	_, err := o.Send(to)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	store.resend = func() {
+		_, err := o.Send(to)
+		assert.NoError(t, err)
+	}
+
+	// The new code is sent between reading and verifying the previous one, it is the one verified.
+	assert.ErrorIs(t, o.Verify(to, gateway.codes[0]), ErrMismatched)
+
+	if assert.Len(t, gateway.codes, 2) {
+		assert.NoError(t, o.Verify(to, gateway.codes[1]))
+	}
+}
@@ -0,0 +1,169 @@
+package otp
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// Entry A sent verification code.
+type Entry struct {
+	// Hash Hash of the code, the code itself is never stored.
+	Hash     []byte
+	Attempts int
+	SentAt   time.Time
+}
+
+// Store Store the sent verification codes by phone number.
+type Store interface {
+	// Get Get the entry of the key, nil if there is none or it expired.
+	Get(key string) (*Entry, error)
+	// Set Set the entry of the key, it expires after the ttl.
+	Set(key string, entry *Entry, ttl time.Duration) error
+	// CompareAndSwap Replace the entry of the key atomically if it is still old, nil for none, and report whether it
+	// was replaced. Entries are compared by Hash and SentAt, a nil entry deletes the key.
+	CompareAndSwap(key string, old, entry *Entry, ttl time.Duration) (bool, error)
+	// IncrAttempts Increase the attempts of the key atomically and return the entry with them, nil if there is none.
+	IncrAttempts(key string) (*Entry, error)
+	// Delete Delete the key.
+	Delete(key string) error
+}
+
+// memoryStoreSweepInterval Number of Set calls between sweeps of expired entries.
+const memoryStoreSweepInterval = 1024
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore In-memory store, codes are not shared between processes.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	sets    int
+	now     func() time.Time
+}
+
+// memoryEntry Entry with its expiry.
+type memoryEntry struct {
+	entry     Entry
+	expiresAt time.Time
+}
+
+// NewMemoryStore New an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryStore) Get(key string) (*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.get(key)
+	if e == nil {
+		return nil, nil
+	}
+
+	entry := e.entry
+	return &entry, nil
+}
+
+func (m *MemoryStore) Set(key string, entry *Entry, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(key, entry, ttl)
+
+	return nil
+}
+
+func (m *MemoryStore) CompareAndSwap(key string, old, entry *Entry, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var current *Entry
+	if e := m.get(key); e != nil {
+		current = &e.entry
+	}
+
+	if !sameEntry(current, old) {
+		return false, nil
+	}
+
+	if entry == nil {
+		delete(m.entries, key)
+		return true, nil
+	}
+
+	m.set(key, entry, ttl)
+
+	return true, nil
+}
+
+// set Set the entry, expired entries are swept every memoryStoreSweepInterval calls.
+func (m *MemoryStore) set(key string, entry *Entry, ttl time.Duration) {
+	now := m.now()
+
+	m.sets++
+	if m.sets%memoryStoreSweepInterval == 0 {
+		for k, e := range m.entries {
+			if !now.Before(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+	}
+
+	m.entries[key] = &memoryEntry{
+		entry:     *entry,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+func (m *MemoryStore) IncrAttempts(key string) (*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.get(key)
+	if e == nil {
+		return nil, nil
+	}
+
+	e.entry.Attempts++
+
+	entry := e.entry
+	return &entry, nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+
+	return nil
+}
+
+// get Get the entry, expired entries are deleted.
+func (m *MemoryStore) get(key string) *memoryEntry {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if !m.now().Before(e.expiresAt) {
+		delete(m.entries, key)
+		return nil
+	}
+
+	return e
+}
+
+// sameEntry Whether the entries are the same code, nil is only the same as nil.
+func sameEntry(a, b *Entry) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return bytes.Equal(a.Hash, b.Hash) && a.SentAt.Equal(b.SentAt)
+}
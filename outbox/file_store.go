@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordExt Extension of the record files.
+const recordExt = ".json"
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore Store keeping a JSON file per record in a directory, files are replaced atomically.
+// A directory must be used by one process at a time.
+type FileStore struct {
+	dir string
+
+	mu      sync.Mutex
+	records *records
+}
+
+// NewFileStore Open the store in the directory, it is created if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FileStore{
+		dir:     dir,
+		records: newRecords(),
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), recordExt) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, err
+		}
+
+		if err := f.records.add(&record); err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (f *FileStore) Enqueue(record *Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !validID(record.ID) {
+		return ErrInvalidRecordID
+	}
+
+	if err := f.records.add(record); err != nil {
+		return err
+	}
+
+	if err := f.write(record); err != nil {
+		delete(f.records.items, record.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (f *FileStore) Lease(limit int, duration time.Duration) ([]*Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	leased, previous := f.records.lease(limit, duration)
+
+	for i, record := range leased {
+		if err := f.write(record); err != nil {
+			// Roll back the lease, the records already written are released on a best-effort basis.
+			f.records.restore(previous)
+			for _, record := range previous[:i] {
+				_ = f.write(record)
+			}
+
+			return nil, err
+		}
+	}
+
+	return leased, nil
+}
+
+func (f *FileStore) Ack(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.records.items[id]; !ok {
+		return ErrRecordNotFound
+	}
+
+	// The file is removed first, so a failure keeps the record in memory as it is on disk.
+	if err := os.Remove(f.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := f.records.remove(id); err != nil {
+		return err
+	}
+
+	return f.syncDir()
+}
+
+func (f *FileStore) Nack(id string, reason error, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.update(id, func() (*Record, error) {
+		return f.records.nack(id, reason, retryAt)
+	})
+}
+
+func (f *FileStore) Postpone(id string, reason error, retryAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.update(id, func() (*Record, error) {
+		return f.records.postpone(id, reason, retryAt)
+	})
+}
+
+func (f *FileStore) Dead(id string, reason error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.update(id, func() (*Record, error) {
+		return f.records.dead(id, reason)
+	})
+}
+
+// DeadLetters Records in the dead-letter state.
+func (f *FileStore) DeadLetters() []*Record {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.records.deadLetters()
+}
+
+// Len Number of records not sent yet, including dead letters.
+func (f *FileStore) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.records.items)
+}
+
+// update Change the record in memory and write it, the change is rolled back if the write fails.
+func (f *FileStore) update(id string, change func() (*Record, error)) error {
+	item, ok := f.records.items[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+
+	previous := *item
+
+	record, err := change()
+	if err != nil {
+		return err
+	}
+
+	if err := f.write(record); err != nil {
+		f.records.restore([]*Record{&previous})
+		return err
+	}
+
+	return nil
+}
+
+// write Write the record to a temporary file and rename it over the record file.
+func (f *FileStore) write(record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(f.dir, record.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), f.path(record.ID)); err != nil {
+		return err
+	}
+
+	return f.syncDir()
+}
+
+// syncDir Flush the directory so that renamed and removed record files survive a crash.
+func (f *FileStore) syncDir() error {
+	dir, err := os.Open(f.dir)
+	if err != nil {
+		return err
+	}
+
+	if err := dir.Sync(); err != nil {
+		dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
+
+// validID Whether the record id can be used as a file name in the directory.
+func validID(id string) bool {
+	return id != "" && id != "." && !strings.Contains(id, "..") && !strings.ContainsAny(id, "/\\\x00")
+}
+
+// path Path of the record file.
+func (f *FileStore) path(id string) string {
+	return filepath.Join(f.dir, id+recordExt)
+}
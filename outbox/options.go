@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"time"
+)
+
+type RelayOption func(*Relay)
+
+// WithBatchSize set the number of records leased at a time.
+func WithBatchSize(size int) func(*Relay) {
+	return func(r *Relay) {
+		r.batchSize = size
+	}
+}
+
+// WithPollInterval set the interval of polling the store when there is nothing to send.
+func WithPollInterval(interval time.Duration) func(*Relay) {
+	return func(r *Relay) {
+		r.pollInterval = interval
+	}
+}
+
+// WithLeaseDuration set how long a record is leased, it must be longer than sending takes.
+func WithLeaseDuration(duration time.Duration) func(*Relay) {
+	return func(r *Relay) {
+		r.leaseDuration = duration
+	}
+}
+
+// WithMaxAttempts set the attempts before a record is moved to the dead-letter state.
+func WithMaxAttempts(maxAttempts int) func(*Relay) {
+	return func(r *Relay) {
+		r.maxAttempts = maxAttempts
+	}
+}
+
+// WithBackoff set the backoff of the first retry and the maximum backoff, it doubles after each attempt.
+func WithBackoff(base, max time.Duration) func(*Relay) {
+	return func(r *Relay) {
+		r.baseBackoff = base
+		r.maxBackoff = max
+	}
+}
+
+// WithLogger set the logger.
+func WithLogger(logger gsms.Logger) func(*Relay) {
+	return func(r *Relay) {
+		r.logger = logger
+	}
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"github.com/google/uuid"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"time"
+)
+
+// Status Status of a record.
+type Status string
+
+const (
+	// StatusPending waiting to be sent, possibly after NextAttemptAt.
+	StatusPending Status = "pending"
+	// StatusLeased being sent by a relay until LeasedUntil.
+	StatusLeased Status = "leased"
+	// StatusDead failed permanently or too many times, it is not sent again.
+	StatusDead Status = "dead"
+)
+
+// Record A message in the outbox, records are removed once they are sent.
+type Record struct {
+	ID       string            `json:"id"`
+	To       *gsms.PhoneNumber `json:"to"`
+	Message  *message.Payload  `json:"message"`
+	Gateways []string          `json:"gateways,omitempty"`
+	Status   Status            `json:"status"`
+	// Attempts Number of leases, including the ones that did not finish.
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LeasedUntil   time.Time `json:"leased_until"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewRecord New a pending record sent via the gateways, the gateways of the message or gsms are used if there are none.
+func NewRecord(to *gsms.PhoneNumber, payload *message.Payload, gateways ...string) *Record {
+	now := time.Now()
+
+	return &Record{
+		ID:            uuid.New().String(),
+		To:            to,
+		Message:       payload,
+		Gateways:      gateways,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// leasable Whether the record can be leased now.
+func (r *Record) leasable(now time.Time) bool {
+	switch r.Status {
+	case StatusPending:
+		return !now.Before(r.NextAttemptAt)
+	case StatusLeased:
+		return !now.Before(r.LeasedUntil)
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"time"
+)
+
+const (
+	DefaultBatchSize     = 100
+	DefaultPollInterval  = time.Second
+	DefaultLeaseDuration = time.Minute
+	DefaultMaxAttempts   = 10
+	DefaultBaseBackoff   = 5 * time.Second
+	DefaultMaxBackoff    = 30 * time.Minute
+)
+
+// Relay Send the records of the outbox via gsms.
+// Failed records are retried with exponential backoff, records failing permanently or too many times are moved
+// to the dead-letter state. Records outside the send window are not failures, they are postponed to the next window
+// without counting the attempt.
+type Relay struct {
+	client *gsms.Gsms
+	store  Store
+
+	batchSize     int
+	pollInterval  time.Duration
+	leaseDuration time.Duration
+	maxAttempts   int
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	logger        gsms.Logger
+
+	now func() time.Time
+}
+
+// NewRelay New a relay sending the records of the store with the client.
+func NewRelay(client *gsms.Gsms, store Store, options ...RelayOption) *Relay {
+	r := &Relay{
+		client:        client,
+		store:         store,
+		batchSize:     DefaultBatchSize,
+		pollInterval:  DefaultPollInterval,
+		leaseDuration: DefaultLeaseDuration,
+		maxAttempts:   DefaultMaxAttempts,
+		baseBackoff:   DefaultBaseBackoff,
+		maxBackoff:    DefaultMaxBackoff,
+		logger:        gsms.NewLogger(),
+		now:           time.Now,
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Enqueue Add a message to the outbox, the message is evaluated for all gateways of the client.
+func (r *Relay) Enqueue(to *gsms.PhoneNumber, msg gsms.Message, gateways ...string) (string, error) {
+	payload, err := message.NewPayload(msg, append(r.client.GatewayNames(), gateways...)...)
+	if err != nil {
+		return "", err
+	}
+
+	record := NewRecord(to, payload, gateways...)
+
+	if err := r.store.Enqueue(record); err != nil {
+		return "", err
+	}
+
+	return record.ID, nil
+}
+
+// Run Relay the records until the context is done.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			r.logger.Errorf("relay outbox failed: %+v", err)
+		}
+
+		if n > 0 && err == nil {
+			continue
+		}
+
+		timer := time.NewTimer(r.pollInterval)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// RelayOnce Lease a batch of records and send them, it returns the number of leased records.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	records, err := r.store.Lease(r.batchSize, r.leaseDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	var firstErr error
+	for _, record := range records {
+		if err := r.deliver(ctx, record); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return len(records), firstErr
+}
+
+// deliver Send the record and ack, nack or bury it.
+func (r *Relay) deliver(ctx context.Context, record *Record) error {
+	_, err := r.client.SendContext(ctx, record.To, record.Message, record.Gateways...)
+
+	var deferred *gsms.ErrSendDeferred
+	var outside *gsms.ErrOutsideSendWindow
+
+	switch {
+	case err == nil:
+		return r.store.Ack(record.ID)
+	case errors.As(err, &deferred):
+		return r.deferred(record, deferred)
+	case errors.As(err, &outside):
+		r.logger.Infof("outbox record %s is outside the send window, it is postponed to %s", record.ID, outside.NextWindow)
+		return r.store.Postpone(record.ID, err, outside.NextWindow)
+	case ctx.Err() != nil:
+		return r.store.Nack(record.ID, err, r.now())
+	case isPermanent(err) || record.Attempts >= r.maxAttempts:
+		r.logger.Warnf("outbox record %s is dead after %d attempts: %+v", record.ID, record.Attempts, err)
+		return r.store.Dead(record.ID, err)
+	default:
+		return r.store.Nack(record.ID, err, r.now().Add(r.backoff(record.Attempts, err)))
+	}
+}
+
+// deferred Handle a record deferred to the next send window, it is not a failure.
+// The copy scheduled by gsms may not be durable, so it is cancelled and the relay sends the record once it is due.
+// If it can not be cancelled, e.g. it is being sent, the record is acked.
+func (r *Relay) deferred(record *Record, deferred *gsms.ErrSendDeferred) error {
+	if err := deferred.Handle.Cancel(); err != nil {
+		r.logger.Infof("outbox record %s is deferred to %s", record.ID, deferred.Handle.At)
+		return r.store.Ack(record.ID)
+	}
+
+	r.logger.Infof("outbox record %s is deferred to %s, it is kept in the outbox", record.ID, deferred.Handle.At)
+
+	return r.store.Postpone(record.ID, deferred, deferred.Handle.At)
+}
+
+// backoff Backoff after the attempt, ErrRateLimited.RetryAfter is respected.
+func (r *Relay) backoff(attempt int, err error) time.Duration {
+	backoff := r.baseBackoff
+	for i := 1; i < attempt && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > r.maxBackoff {
+		backoff = r.maxBackoff
+	}
+
+	var rateLimited *gsms.ErrRateLimited
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > backoff {
+		backoff = rateLimited.RetryAfter
+	}
+
+	return backoff
+}
+
+// isPermanent Whether sending again can not succeed, e.g. the recipient is suppressed or the number or the template
+// is invalid on every gateway.
+func isPermanent(err error) bool {
+	var suppressed *gsms.ErrRecipientSuppressed
+	if errors.As(err, &suppressed) {
+		return true
+	}
+
+	var failed *gsms.ErrGatewaysFailed
+	if !errors.As(err, &failed) {
+		return errors.Is(err, gsms.ErrInvalidPhoneNumber)
+	}
+
+	if len(failed.Results) == 0 {
+		return false
+	}
+
+	for _, result := range failed.Results {
+		switch gsms.ClassifyError(result.Error) {
+		case gsms.ErrorCodeInvalidNumber, gsms.ErrorCodeTemplateNotApproved, gsms.ErrorCodeSignatureInvalid:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
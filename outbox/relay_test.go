@@ -0,0 +1,265 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testGateway struct {
+	err       error
+	templates []string
+}
+
+func (t *testGateway) Name() string {
+	return "test"
+}
+
+func (t *testGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	template, _ := message.GetTemplate(t)
+	t.templates = append(t.templates, template)
+
+	return nil
+}
+
+func newTestRelay(gateway *testGateway, store Store, options ...RelayOption) *Relay {
+	client := gsms.New([]gsms.Gateway{gateway}, gsms.WithGateways([]string{"test"}))
+	return NewRelay(client, store, append([]RelayOption{WithLogger(gsms.NewLogger().LogMode(gsms.Silent))}, options...)...)
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	gateway := &testGateway{}
+	store := NewMemoryStore()
+	relay := newTestRelay(gateway, store)
+
+	_, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{
+		Template: func(gateway gsms.Gateway) string {
+			if gateway.Name() == "test" {
+				return "SMS_271311117"
+			}
+			return "5532011"
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	n, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"SMS_271311117"}, gateway.templates)
+	assert.Zero(t, store.Len())
+}
+
+func TestRelay_RelayOnce_Retry(t *testing.T) {
+	gateway := &testGateway{err: context.DeadlineExceeded}
+	store := NewMemoryStore()
+	relay := newTestRelay(gateway, store, WithBackoff(time.Second, 3*time.Second), WithMaxAttempts(3))
+
+	_, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{Template: "SMS_00000001"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	now := time.Now()
+	store.records.now = func() time.Time { return now }
+	relay.now = func() time.Time { return now }
+
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		n, _ := relay.RelayOnce(context.Background())
+		assert.Equal(t, 1, n)
+
+		now = now.Add(backoff - time.Millisecond)
+		n, _ = relay.RelayOnce(context.Background())
+		assert.Equal(t, 0, n)
+
+		now = now.Add(time.Millisecond)
+	}
+
+	n, _ := relay.RelayOnce(context.Background())
+	assert.Equal(t, 1, n)
+
+	if dead := store.DeadLetters(); assert.Len(t, dead, 1) {
+		assert.Equal(t, 3, dead[0].Attempts)
+		assert.NotEmpty(t, dead[0].LastError)
+	}
+}
+
+func TestRelay_RelayOnce_Permanent(t *testing.T) {
+	gateway := &testGateway{err: gsms.NewSendError("test", gsms.ErrorCodeInvalidNumber, "", "")}
+	store := NewMemoryStore()
+	relay := newTestRelay(gateway, store)
+
+	_, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{Template: "SMS_00000001"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, store.DeadLetters(), 1)
+}
+
+func TestRelay_Run(t *testing.T) {
+	gateway := &testGateway{}
+	store := NewMemoryStore()
+	relay := newTestRelay(gateway, store, WithPollInterval(time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		_, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{Template: "SMS_00000001"})
+		assert.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- relay.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool { return store.Len() == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.True(t, errors.Is(<-done, context.Canceled))
+}
+
+// keepScheduleStore Schedule store whose sends can not be removed, as if they were being sent.
+type keepScheduleStore struct {
+	*gsms.MemoryScheduleStore
+}
+
+func (k *keepScheduleStore) Remove(id string) error {
+	return gsms.ErrScheduleNotFound
+}
+
+// newDeferringClient New a client deferring every send to the window starting two hours after now in UTC.
+func newDeferringClient(gateway *testGateway, store gsms.ScheduleStore) (*gsms.Gsms, time.Time) {
+	return newWindowClient(gateway, store, true)
+}
+
+// newWindowClient New a client whose send window starts two hours after now in UTC.
+func newWindowClient(gateway *testGateway, store gsms.ScheduleStore, deferred bool) (*gsms.Gsms, time.Time) {
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := now.Add(2 * time.Hour).Truncate(time.Minute)
+
+	client := gsms.New([]gsms.Gateway{gateway},
+		gsms.WithGateways([]string{"test"}),
+		gsms.WithScheduleStore(store),
+		gsms.WithSendWindowPolicy(&gsms.SendWindowPolicy{
+			Default: &gsms.SendWindow{
+				Start: start.Sub(midnight) % (24 * time.Hour),
+				End:   start.Add(time.Hour).Sub(midnight) % (24 * time.Hour),
+			},
+			Locations: map[int]*time.Location{86: time.UTC},
+			Defer:     deferred,
+		}),
+	)
+
+	return client, start
+}
+
+func TestRelay_RelayOnce_Deferred(t *testing.T) {
+	gateway := &testGateway{}
+	schedules := gsms.NewMemoryScheduleStore(time.Second)
+
+	client, start := newDeferringClient(gateway, schedules)
+	defer client.Close()
+
+	store := NewMemoryStore()
+	relay := NewRelay(client, store, WithLogger(gsms.NewLogger().LogMode(gsms.Silent)))
+
+	id, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{
+		Template: "SMS_00000001",
+		Category: gsms.CategoryMarketing,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	n, err := relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The copy scheduled by gsms is cancelled, the record is kept in the outbox until the window opens.
+	assert.Zero(t, schedules.Len())
+	assert.Empty(t, gateway.templates)
+
+	if record, ok := store.records.items[id]; assert.True(t, ok) {
+		assert.Equal(t, StatusPending, record.Status)
+		assert.True(t, start.Equal(record.NextAttemptAt))
+		assert.Zero(t, record.Attempts)
+	}
+
+	assert.Empty(t, store.DeadLetters())
+}
+
+func TestRelay_RelayOnce_Deferred_Ack(t *testing.T) {
+	gateway := &testGateway{}
+	schedules := &keepScheduleStore{gsms.NewMemoryScheduleStore(time.Second)}
+
+	client, _ := newDeferringClient(gateway, schedules)
+	defer client.Close()
+
+	store := NewMemoryStore()
+	relay := NewRelay(client, store, WithLogger(gsms.NewLogger().LogMode(gsms.Silent)))
+
+	_, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{
+		Template: "SMS_00000001",
+		Category: gsms.CategoryMarketing,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = relay.RelayOnce(context.Background())
+	assert.NoError(t, err)
+
+	// The copy scheduled by gsms can not be cancelled, it is sent by gsms and the record is acked.
+	assert.Equal(t, 1, schedules.Len())
+	assert.Zero(t, store.Len())
+}
+
+func TestRelay_RelayOnce_OutsideSendWindow(t *testing.T) {
+	gateway := &testGateway{}
+
+	client, start := newWindowClient(gateway, gsms.NewMemoryScheduleStore(time.Second), false)
+	defer client.Close()
+
+	store := NewMemoryStore()
+	relay := NewRelay(client, store, WithMaxAttempts(1), WithLogger(gsms.NewLogger().LogMode(gsms.Silent)))
+
+	id, err := relay.Enqueue(gsms.NewPhoneNumber(18888888888, "86"), &message.Message{
+		Template: "SMS_00000001",
+		Category: gsms.CategoryMarketing,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Records outside the window are postponed to it without counting toward the max attempts.
+	for i := 0; i < 3; i++ {
+		n, err := relay.RelayOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		if record, ok := store.records.items[id]; assert.True(t, ok) {
+			assert.Equal(t, StatusPending, record.Status)
+			assert.True(t, start.Equal(record.NextAttemptAt))
+			assert.Zero(t, record.Attempts)
+		}
+
+		store.records.now = func() time.Time { return start }
+	}
+
+	assert.Empty(t, gateway.templates)
+	assert.Empty(t, store.DeadLetters())
+}
@@ -0,0 +1,266 @@
+package outbox
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRecordNotFound  = errors.New("outbox record not found")
+	ErrRecordExists    = errors.New("outbox record already exists")
+	ErrInvalidRecordID = errors.New("invalid outbox record id")
+)
+
+// Store Durable store of the outbox.
+// A leased record is not leased again until its lease expires, so a record is sent at least once.
+type Store interface {
+	// Enqueue Add a pending record.
+	Enqueue(record *Record) error
+	// Lease Lease up to limit records that are due, oldest first, and increase their attempts.
+	Lease(limit int, duration time.Duration) ([]*Record, error)
+	// Ack Remove the record once it is sent.
+	Ack(id string) error
+	// Nack Release the record to be sent again at retryAt.
+	Nack(id string, reason error, retryAt time.Time) error
+	// Postpone Release the record to be sent at retryAt without counting the attempt, e.g. outside the send window.
+	Postpone(id string, reason error, retryAt time.Time) error
+	// Dead Move the record to the dead-letter state.
+	Dead(id string, reason error) error
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore In-memory store, records are lost on restart, useful for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records *records
+}
+
+// NewMemoryStore New an in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: newRecords()}
+}
+
+func (m *MemoryStore) Enqueue(record *Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.records.add(record)
+}
+
+func (m *MemoryStore) Lease(limit int, duration time.Duration) ([]*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	leased, _ := m.records.lease(limit, duration)
+	return leased, nil
+}
+
+func (m *MemoryStore) Ack(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.records.remove(id)
+}
+
+func (m *MemoryStore) Nack(id string, reason error, retryAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.records.nack(id, reason, retryAt)
+	return err
+}
+
+func (m *MemoryStore) Postpone(id string, reason error, retryAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.records.postpone(id, reason, retryAt)
+	return err
+}
+
+func (m *MemoryStore) Dead(id string, reason error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.records.dead(id, reason)
+	return err
+}
+
+// DeadLetters Records in the dead-letter state.
+func (m *MemoryStore) DeadLetters() []*Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.records.deadLetters()
+}
+
+// Len Number of records not sent yet, including dead letters.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.records.items)
+}
+
+// records Records indexed by id, callers hold the lock.
+// Records are copied in and out so callers never share them with the store.
+type records struct {
+	items map[string]*Record
+	now   func() time.Time
+}
+
+// newRecords New empty records.
+func newRecords() *records {
+	return &records{
+		items: map[string]*Record{},
+		now:   time.Now,
+	}
+}
+
+// add Add a record.
+func (r *records) add(record *Record) error {
+	if _, ok := r.items[record.ID]; ok {
+		return ErrRecordExists
+	}
+
+	item := *record
+	r.items[record.ID] = &item
+
+	return nil
+}
+
+// lease Lease the due records, the records as they were before the lease are returned to roll it back.
+func (r *records) lease(limit int, duration time.Duration) ([]*Record, []*Record) {
+	now := r.now()
+
+	var due []*Record
+	for _, item := range r.items {
+		if item.leasable(now) {
+			due = append(due, item)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].CreatedAt.Equal(due[j].CreatedAt) {
+			return due[i].CreatedAt.Before(due[j].CreatedAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	leased := make([]*Record, 0, len(due))
+	previous := make([]*Record, 0, len(due))
+	for _, item := range due {
+		before := *item
+		previous = append(previous, &before)
+
+		item.Status = StatusLeased
+		item.LeasedUntil = now.Add(duration)
+		item.Attempts++
+		item.UpdatedAt = now
+
+		record := *item
+		leased = append(leased, &record)
+	}
+
+	return leased, previous
+}
+
+// restore Replace the records with the copies, e.g. to roll back a lease.
+func (r *records) restore(records []*Record) {
+	for _, record := range records {
+		item := *record
+		r.items[record.ID] = &item
+	}
+}
+
+// remove Remove the record.
+func (r *records) remove(id string) error {
+	if _, ok := r.items[id]; !ok {
+		return ErrRecordNotFound
+	}
+
+	delete(r.items, id)
+
+	return nil
+}
+
+// nack Release the record and return a copy of it.
+func (r *records) nack(id string, reason error, retryAt time.Time) (*Record, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	item.Status = StatusPending
+	item.NextAttemptAt = retryAt
+	item.LeasedUntil = time.Time{}
+	item.LastError = errorString(reason)
+	item.UpdatedAt = r.now()
+
+	record := *item
+	return &record, nil
+}
+
+// postpone Release the record like nack without counting the attempt and return a copy of it.
+func (r *records) postpone(id string, reason error, retryAt time.Time) (*Record, error) {
+	if _, err := r.nack(id, reason, retryAt); err != nil {
+		return nil, err
+	}
+
+	item := r.items[id]
+	if item.Attempts > 0 {
+		item.Attempts--
+	}
+
+	record := *item
+	return &record, nil
+}
+
+// dead Move the record to the dead-letter state and return a copy of it.
+func (r *records) dead(id string, reason error) (*Record, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	item.Status = StatusDead
+	item.LeasedUntil = time.Time{}
+	item.LastError = errorString(reason)
+	item.UpdatedAt = r.now()
+
+	record := *item
+	return &record, nil
+}
+
+// deadLetters Copies of the dead records, oldest first.
+func (r *records) deadLetters() []*Record {
+	var dead []*Record
+	for _, item := range r.items {
+		if item.Status == StatusDead {
+			record := *item
+			dead = append(dead, &record)
+		}
+	}
+
+	sort.Slice(dead, func(i, j int) bool {
+		return dead[i].CreatedAt.Before(dead[j].CreatedAt)
+	})
+
+	return dead
+}
+
+// errorString Error message, empty if err is nil.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
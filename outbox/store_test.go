@@ -0,0 +1,231 @@
+package outbox
+
+import (
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestRecord(number int) *Record {
+	return NewRecord(gsms.NewPhoneNumber(number, "86"), &message.Payload{Template: "SMS_00000001"}, "aliyun")
+}
+
+func testStore(t *testing.T, store Store, records *records) {
+	first := newTestRecord(18800000001)
+	second := newTestRecord(18800000002)
+	second.CreatedAt = first.CreatedAt.Add(time.Millisecond)
+
+	now := second.CreatedAt
+	records.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Enqueue(second))
+	assert.NoError(t, store.Enqueue(first))
+	assert.ErrorIs(t, store.Enqueue(first), ErrRecordExists)
+
+	leased, err := store.Lease(1, time.Minute)
+	if assert.NoError(t, err) && assert.Len(t, leased, 1) {
+		assert.Equal(t, first.ID, leased[0].ID)
+		assert.Equal(t, StatusLeased, leased[0].Status)
+		assert.Equal(t, 1, leased[0].Attempts)
+	}
+
+	leased, _ = store.Lease(10, time.Minute)
+	if assert.Len(t, leased, 1) {
+		assert.Equal(t, second.ID, leased[0].ID)
+	}
+
+	leased, _ = store.Lease(10, time.Minute)
+	assert.Empty(t, leased)
+
+	// Expired leases are leased again.
+	now = now.Add(time.Minute)
+	leased, _ = store.Lease(10, time.Minute)
+	assert.Len(t, leased, 2)
+
+	assert.NoError(t, store.Ack(first.ID))
+	assert.ErrorIs(t, store.Ack(first.ID), ErrRecordNotFound)
+
+	assert.NoError(t, store.Nack(second.ID, errors.New("send failed"), now.Add(time.Second)))
+
+	leased, _ = store.Lease(10, time.Minute)
+	assert.Empty(t, leased)
+
+	now = now.Add(time.Second)
+	leased, _ = store.Lease(10, time.Minute)
+	if assert.Len(t, leased, 1) {
+		assert.Equal(t, 3, leased[0].Attempts)
+		assert.Equal(t, "send failed", leased[0].LastError)
+	}
+
+	// Postponed records are not counted as attempts.
+	assert.NoError(t, store.Postpone(second.ID, errors.New("outside send window"), now.Add(time.Second)))
+
+	now = now.Add(time.Second)
+	leased, _ = store.Lease(10, time.Minute)
+	if assert.Len(t, leased, 1) {
+		assert.Equal(t, 3, leased[0].Attempts)
+		assert.Equal(t, "outside send window", leased[0].LastError)
+	}
+
+	assert.NoError(t, store.Dead(second.ID, errors.New("invalid number")))
+
+	now = now.Add(time.Hour)
+	leased, _ = store.Lease(10, time.Minute)
+	assert.Empty(t, leased)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	testStore(t, store, store.records)
+
+	dead := store.DeadLetters()
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, StatusDead, dead[0].Status)
+		assert.Equal(t, "invalid number", dead[0].LastError)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testStore(t, store, store.records)
+
+	pending := newTestRecord(18800000003)
+	assert.NoError(t, store.Enqueue(pending))
+
+	reopened, err := NewFileStore(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 2, reopened.Len())
+
+	dead := reopened.DeadLetters()
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, "invalid number", dead[0].LastError)
+		assert.Equal(t, 18800000002, dead[0].To.Number())
+		assert.Equal(t, 86, dead[0].To.IDDCode())
+	}
+
+	leased, _ := reopened.Lease(10, time.Minute)
+	if assert.Len(t, leased, 1) {
+		assert.Equal(t, pending.ID, leased[0].ID)
+		assert.Equal(t, "SMS_00000001", leased[0].Message.Template)
+		assert.Equal(t, []string{"aliyun"}, leased[0].Gateways)
+	}
+}
+
+func TestFileStore_Lease_Rollback(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	first := newTestRecord(18800000001)
+	second := newTestRecord(18800000002)
+	second.CreatedAt = first.CreatedAt.Add(time.Millisecond)
+
+	assert.NoError(t, store.Enqueue(first))
+	assert.NoError(t, store.Enqueue(second))
+
+	// A non-empty directory in place of the record file makes writing the second record fail.
+	blocked := store.path(second.ID)
+	assert.NoError(t, os.Remove(blocked))
+	assert.NoError(t, os.MkdirAll(filepath.Join(blocked, "blocked"), 0o755))
+
+	_, err = store.Lease(10, time.Minute)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.RemoveAll(blocked))
+
+	reopened, err := NewFileStore(dir)
+	if assert.NoError(t, err) {
+		leased, _ := reopened.Lease(10, time.Minute)
+		if assert.Len(t, leased, 1) {
+			assert.Equal(t, first.ID, leased[0].ID)
+			assert.Equal(t, 1, leased[0].Attempts)
+		}
+	}
+
+	leased, err := store.Lease(10, time.Minute)
+	if assert.NoError(t, err) && assert.Len(t, leased, 2) {
+		assert.Equal(t, 1, leased[0].Attempts)
+		assert.Equal(t, 1, leased[1].Attempts)
+	}
+}
+
+func TestFileStore_Enqueue_InvalidID(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(filepath.Join(dir, "outbox"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, id := range []string{"", ".", "..", "../x", "../../x", `..\x`, "a/b", `a\b`} {
+		record := newTestRecord(18800000001)
+		record.ID = id
+
+		assert.ErrorIs(t, store.Enqueue(record), ErrInvalidRecordID, id)
+	}
+
+	assert.Zero(t, store.Len())
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+}
+
+func TestFileStore_Rollback(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	record := newTestRecord(18800000001)
+	assert.NoError(t, store.Enqueue(record))
+
+	leased, err := store.Lease(10, time.Minute)
+	if !assert.NoError(t, err) || !assert.Len(t, leased, 1) {
+		return
+	}
+
+	// A non-empty directory in place of the record file makes writing and removing it fail.
+	blocked := store.path(record.ID)
+	assert.NoError(t, os.Remove(blocked))
+	assert.NoError(t, os.MkdirAll(filepath.Join(blocked, "blocked"), 0o755))
+
+	reason := errors.New("send failed")
+
+	assert.Error(t, store.Ack(record.ID))
+	assert.Error(t, store.Nack(record.ID, reason, time.Now()))
+	assert.Error(t, store.Postpone(record.ID, reason, time.Now()))
+	assert.Error(t, store.Dead(record.ID, reason))
+
+	// The record is still leased in memory.
+	assert.Equal(t, 1, store.Len())
+	assert.Empty(t, store.DeadLetters())
+
+	if item, ok := store.records.items[record.ID]; assert.True(t, ok) {
+		assert.Equal(t, leased[0], item)
+	}
+
+	assert.NoError(t, os.RemoveAll(blocked))
+
+	assert.NoError(t, store.Nack(record.ID, reason, time.Now()))
+	assert.NoError(t, store.Ack(record.ID))
+	assert.Zero(t, store.Len())
+}
@@ -1,6 +1,7 @@
 package gsms
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -58,7 +59,45 @@ func (p *PhoneNumber) String() string {
 	return p.UniversalNumber()
 }
 
+// Masked Universal number with the middle digits masked, e.g. +86138****8000.
+func (p *PhoneNumber) Masked() string {
+	number := strconv.Itoa(p.number)
+
+	head, tail := 3, 4
+	if len(number) <= head+tail {
+		head, tail = 0, 2
+		if len(number) < tail {
+			tail = len(number)
+		}
+	}
+
+	return p.PrefixedIDDCode("+") + number[:head] + strings.Repeat("*", len(number)-head-tail) + number[len(number)-tail:]
+}
+
 // InChineseMainland Check if the phone number belongs to chinese mainland.
 func (p *PhoneNumber) InChineseMainland() bool {
 	return p.iddCode == 86
 }
+
+// phoneNumberJSON JSON representation of a phone number.
+type phoneNumberJSON struct {
+	Number  int `json:"number"`
+	IDDCode int `json:"idd_code,omitempty"`
+}
+
+// MarshalJSON e.g. {"number":13800138000,"idd_code":86}.
+func (p *PhoneNumber) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&phoneNumberJSON{Number: p.number, IDDCode: p.iddCode})
+}
+
+func (p *PhoneNumber) UnmarshalJSON(data []byte) error {
+	var v phoneNumberJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	p.number = v.Number
+	p.iddCode = v.IDDCode
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package gsms
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -69,3 +70,30 @@ func TestPhoneNumber_IDDCode(t *testing.T) {
 		})
 	}
 }
+
+func TestPhoneNumber_Masked(t *testing.T) {
+	assert.Equal(t, "+86138****8000", NewPhoneNumber(13800138000, "86").Masked())
+	assert.Equal(t, "138****8000", NewPhoneNumberWithoutIDDCode(13800138000).Masked())
+	assert.Equal(t, "+852*****78", NewPhoneNumber(2345678, "852").Masked())
+	assert.Equal(t, "7", NewPhoneNumberWithoutIDDCode(7).Masked())
+}
+
+func TestPhoneNumber_JSON(t *testing.T) {
+	for _, phoneNumber := range []*PhoneNumber{
+		NewPhoneNumber(18888888888, "86"),
+		NewPhoneNumberWithoutIDDCode(18888888888),
+	} {
+		data, err := json.Marshal(phoneNumber)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var got PhoneNumber
+		if assert.NoError(t, json.Unmarshal(data, &got)) {
+			assert.Equal(t, phoneNumber, &got)
+		}
+	}
+
+	data, _ := json.Marshal(NewPhoneNumber(18888888888, "86"))
+	assert.JSONEq(t, `{"number":18888888888,"idd_code":86}`, string(data))
+}
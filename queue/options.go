@@ -0,0 +1,24 @@
+package queue
+
+type Option func(*Queue)
+
+// WithWorkers set the number of workers sending concurrently.
+func WithWorkers(workers int) func(*Queue) {
+	return func(q *Queue) {
+		q.workers = workers
+	}
+}
+
+// WithBufferSize set the number of jobs buffered before enqueueing blocks or fails.
+func WithBufferSize(size int) func(*Queue) {
+	return func(q *Queue) {
+		q.bufferSize = size
+	}
+}
+
+// WithCallback set the callback called by the worker after every job.
+func WithCallback(callback func(result *JobResult)) func(*Queue) {
+	return func(q *Queue) {
+		q.callback = callback
+	}
+}
@@ -0,0 +1,215 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/maiqingqiang/gsms"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultWorkers    = 4
+	DefaultBufferSize = 1024
+)
+
+var (
+	ErrQueueFull   = errors.New("queue is full")
+	ErrQueueClosed = errors.New("queue is closed")
+)
+
+// Job A message waiting to be sent.
+type Job struct {
+	ID         string
+	To         interface{}
+	Message    gsms.Message
+	Gateways   []string
+	EnqueuedAt time.Time
+
+	done chan *JobResult
+}
+
+// JobResult Outcome of a job, Results and Error are what gsms.Send returned.
+type JobResult struct {
+	Job     *Job
+	Results []*gsms.Result
+	Error   error
+	// Duration Duration from enqueueing to the end of the send.
+	Duration time.Duration
+}
+
+// Queue Send messages asynchronously with a pool of workers.
+// Jobs are buffered up to the buffer size, Enqueue fails with ErrQueueFull and EnqueueContext waits when it is full.
+type Queue struct {
+	client *gsms.Gsms
+
+	workers    int
+	bufferSize int
+	callback   func(result *JobResult)
+
+	jobs    chan *Job
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	once    sync.Once
+}
+
+// New a queue and start its workers.
+func New(client *gsms.Gsms, options ...Option) *Queue {
+	q := &Queue{
+		client:     client,
+		workers:    DefaultWorkers,
+		bufferSize: DefaultBufferSize,
+		closing:    make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(q)
+	}
+
+	if q.workers < 1 {
+		q.workers = 1
+	}
+
+	if q.bufferSize < 0 {
+		q.bufferSize = 0
+	}
+
+	q.jobs = make(chan *Job, q.bufferSize)
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	q.wg.Add(q.workers)
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Enqueue Enqueue a message, it returns ErrQueueFull at once if the buffer is full.
+func (q *Queue) Enqueue(to interface{}, message gsms.Message, gateways ...string) (string, error) {
+	job, err := q.enqueue(context.Background(), to, message, gateways, false)
+	if err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// EnqueueContext Enqueue a message, it waits for room in the buffer until the context is done.
+func (q *Queue) EnqueueContext(ctx context.Context, to interface{}, message gsms.Message, gateways ...string) (string, error) {
+	job, err := q.enqueue(ctx, to, message, gateways, true)
+	if err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// Submit Enqueue a message like EnqueueContext and return a channel receiving its result.
+func (q *Queue) Submit(ctx context.Context, to interface{}, message gsms.Message, gateways ...string) (string, <-chan *JobResult, error) {
+	job, err := q.enqueue(ctx, to, message, gateways, true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return job.ID, job.done, nil
+}
+
+// Len Number of jobs waiting in the buffer.
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Shutdown Stop accepting jobs and wait until the pending jobs are sent.
+// If the context is done first, the sends in progress are canceled and the remaining jobs fail with the context error,
+// their results are still delivered.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.once.Do(func() {
+		close(q.closing)
+
+		q.mu.Lock()
+		q.closed = true
+		close(q.jobs)
+		q.mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// enqueue Enqueue the job, waiting for room if wait is true.
+func (q *Queue) enqueue(ctx context.Context, to interface{}, message gsms.Message, gateways []string, wait bool) (*Job, error) {
+	job := &Job{
+		ID:         uuid.New().String(),
+		To:         to,
+		Message:    message,
+		Gateways:   gateways,
+		EnqueuedAt: time.Now(),
+		done:       make(chan *JobResult, 1),
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+
+	if !wait {
+		select {
+		case q.jobs <- job:
+			return job, nil
+		default:
+			return nil, ErrQueueFull
+		}
+	}
+
+	select {
+	case q.jobs <- job:
+		return job, nil
+	case <-q.closing:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// work Send jobs until the queue is closed and drained.
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		results, err := q.client.SendContext(q.ctx, job.To, job.Message, job.Gateways...)
+
+		result := &JobResult{
+			Job:      job,
+			Results:  results,
+			Error:    err,
+			Duration: time.Since(job.EnqueuedAt),
+		}
+
+		job.done <- result
+
+		if q.callback != nil {
+			q.callback(result)
+		}
+	}
+}
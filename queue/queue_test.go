@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockGateway struct {
+	release chan struct{}
+	sent    int32
+	failed  bool
+}
+
+func (b *blockGateway) Name() string {
+	return "block"
+}
+
+func (b *blockGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return b.SendContext(context.Background(), to, message, config)
+}
+
+func (b *blockGateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	if b.release != nil {
+		select {
+		case <-b.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if b.failed {
+		return errors.New("send failed")
+	}
+
+	atomic.AddInt32(&b.sent, 1)
+	return nil
+}
+
+func newClient(gateway gsms.Gateway) *gsms.Gsms {
+	return gsms.New([]gsms.Gateway{gateway}, gsms.WithGateways([]string{"block"}))
+}
+
+var msg = &message.Message{Template: "SMS_00000001"}
+
+func TestQueue_Submit(t *testing.T) {
+	q := New(newClient(&blockGateway{}))
+	defer q.Shutdown(context.Background())
+
+	id, done, err := q.Submit(context.Background(), 18888888888, msg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result := <-done
+	assert.Equal(t, id, result.Job.ID)
+	assert.NoError(t, result.Error)
+	if assert.Len(t, result.Results, 1) {
+		assert.Equal(t, gsms.StatusSuccess, result.Results[0].Status)
+	}
+}
+
+func TestQueue_Callback(t *testing.T) {
+	var mu sync.Mutex
+	var results []*JobResult
+
+	q := New(newClient(&blockGateway{failed: true}), WithCallback(func(result *JobResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, result)
+	}))
+
+	id, err := q.Enqueue(18888888888, msg)
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.Shutdown(context.Background()))
+
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, id, results[0].Job.ID)
+
+		var failed *gsms.ErrGatewaysFailed
+		assert.ErrorAs(t, results[0].Error, &failed)
+	}
+}
+
+func TestQueue_Enqueue_Full(t *testing.T) {
+	gateway := &blockGateway{release: make(chan struct{})}
+	q := New(newClient(gateway), WithWorkers(1), WithBufferSize(1))
+
+	_, done, err := q.Submit(context.Background(), 18888888888, msg)
+	assert.NoError(t, err)
+
+	// Wait until the worker takes the first job.
+	assert.Eventually(t, func() bool { return q.Len() == 0 }, time.Second, time.Millisecond)
+
+	_, err = q.Enqueue(18888888888, msg)
+	assert.NoError(t, err)
+
+	_, err = q.Enqueue(18888888888, msg)
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = q.EnqueueContext(ctx, 18888888888, msg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(gateway.release)
+	<-done
+
+	assert.NoError(t, q.Shutdown(context.Background()))
+	assert.Equal(t, int32(2), gateway.sent)
+
+	_, err = q.Enqueue(18888888888, msg)
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestQueue_Shutdown_Drain(t *testing.T) {
+	gateway := &blockGateway{}
+	q := New(newClient(gateway), WithWorkers(2))
+
+	for i := 0; i < 100; i++ {
+		_, err := q.Enqueue(18888888888, msg)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, q.Shutdown(context.Background()))
+	assert.Equal(t, int32(100), gateway.sent)
+}
+
+func TestQueue_Shutdown_Timeout(t *testing.T) {
+	gateway := &blockGateway{release: make(chan struct{})}
+	q := New(newClient(gateway), WithWorkers(1))
+
+	_, first, err := q.Submit(context.Background(), 18888888888, msg)
+	assert.NoError(t, err)
+
+	_, second, err := q.Submit(context.Background(), 18888888888, msg)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, q.Shutdown(ctx), context.DeadlineExceeded)
+
+	assert.ErrorIs(t, (<-first).Error, context.Canceled)
+	assert.ErrorIs(t, (<-second).Error, context.Canceled)
+	assert.Zero(t, gateway.sent)
+}
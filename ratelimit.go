@@ -0,0 +1,214 @@
+package gsms
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit Token bucket limit of a gateway.
+type RateLimit struct {
+	// QPS Tokens refilled per second.
+	QPS float64
+	// Burst Bucket size, defaults to QPS rounded up and at least 1.
+	Burst int
+}
+
+// burst Bucket size.
+func (l *RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return math.Max(1, math.Ceil(l.QPS))
+}
+
+// WindowLimit Sliding window limit, at most Limit events in any Window.
+type WindowLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// rateLimitSweepInterval Number of TakeWindow calls between sweeps of expired windows.
+const rateLimitSweepInterval = 1024
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// MemoryRateLimitStore In-memory rate limit store, limits are not shared between processes.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	windows map[string]*slidingWindow
+	takes   int
+	now     func() time.Time
+}
+
+// tokenBucket State of a token bucket.
+type tokenBucket struct {
+	tokens   float64
+	filledAt time.Time
+}
+
+// slidingWindow Events of a sliding window in time order.
+type slidingWindow struct {
+	events []time.Time
+	span   time.Duration
+}
+
+// NewMemoryRateLimitStore New an in-memory rate limit store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*tokenBucket{},
+		windows: map[string]*slidingWindow{},
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryRateLimitStore) TakeToken(key string, limit *RateLimit) (time.Duration, error) {
+	if limit == nil || limit.QPS <= 0 {
+		return 0, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	burst := limit.burst()
+
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, filledAt: now}
+		m.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.filledAt).Seconds()*limit.QPS)
+	bucket.filledAt = now
+
+	if bucket.tokens < 1 {
+		return time.Duration(math.Ceil((1 - bucket.tokens) / limit.QPS * float64(time.Second))), nil
+	}
+
+	bucket.tokens--
+
+	return 0, nil
+}
+
+func (m *MemoryRateLimitStore) TakeWindow(key string, limits []*WindowLimit) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	m.takes++
+	if m.takes%rateLimitSweepInterval == 0 {
+		m.sweep(now)
+	}
+
+	window, ok := m.windows[key]
+	if !ok {
+		window = &slidingWindow{}
+		m.windows[key] = window
+	}
+
+	var retryAfter time.Duration
+
+	for _, limit := range limits {
+		if limit == nil || limit.Window <= 0 || limit.Limit <= 0 {
+			continue
+		}
+
+		if limit.Window > window.span {
+			window.span = limit.Window
+		}
+
+		// events[i:] are in the window.
+		i := len(window.events)
+		for i > 0 && now.Sub(window.events[i-1]) < limit.Window {
+			i--
+		}
+
+		if count := len(window.events) - i; count >= limit.Limit {
+			// Wait until the oldest events leave the window and one more fits.
+			wait := window.events[len(window.events)-limit.Limit].Add(limit.Window).Sub(now)
+			if wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	window.events = append(window.prune(now), now)
+
+	return 0, nil
+}
+
+// prune Drop the events older than the span.
+func (w *slidingWindow) prune(now time.Time) []time.Time {
+	i := 0
+	for i < len(w.events) && now.Sub(w.events[i]) >= w.span {
+		i++
+	}
+
+	return append(w.events[:0], w.events[i:]...)
+}
+
+// sweep Drop the windows without any event in their span.
+func (m *MemoryRateLimitStore) sweep(now time.Time) {
+	for key, window := range m.windows {
+		if window.events = window.prune(now); len(window.events) == 0 {
+			delete(m.windows, key)
+		}
+	}
+}
+
+// gatewayRateLimitKey Rate limit key of the gateway.
+func gatewayRateLimitKey(gateway string) string {
+	return "gsms:ratelimit:gateway:" + gateway
+}
+
+// recipientRateLimitKey Rate limit key of the recipient.
+func recipientRateLimitKey(to *PhoneNumber) string {
+	return "gsms:ratelimit:recipient:" + to.UniversalNumber()
+}
+
+// limitGateway Take a token of the gateway, store errors are logged and let the request through.
+func (g *Gsms) limitGateway(gateway string) error {
+	limit, ok := g.gatewayRateLimits[gateway]
+	if !ok || limit == nil {
+		return nil
+	}
+
+	retryAfter, err := g.rateLimitStore.TakeToken(gatewayRateLimitKey(gateway), limit)
+	if err != nil {
+		g.config.Logger.Warnf("[%s] take rate limit token failed: %+v", gateway, err)
+		return nil
+	}
+
+	if retryAfter > 0 {
+		return NewErrRateLimited(gateway, nil, retryAfter)
+	}
+
+	return nil
+}
+
+// limitRecipient Record a send to the recipient, store errors are logged and let the send through.
+func (g *Gsms) limitRecipient(to *PhoneNumber) error {
+	if len(g.recipientRateLimits) == 0 {
+		return nil
+	}
+
+	retryAfter, err := g.rateLimitStore.TakeWindow(recipientRateLimitKey(to), g.recipientRateLimits)
+	if err != nil {
+		g.config.Logger.Warnf("[%s] take recipient rate limit failed: %+v", to, err)
+		return nil
+	}
+
+	if retryAfter > 0 {
+		return NewErrRateLimited("", to, retryAfter)
+	}
+
+	return nil
+}
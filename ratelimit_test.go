@@ -0,0 +1,236 @@
+package gsms
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore_TakeToken(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	limit := &RateLimit{QPS: 2}
+
+	for i := 0; i < 2; i++ {
+		retryAfter, err := store.TakeToken("a", limit)
+		assert.NoError(t, err)
+		assert.Zero(t, retryAfter)
+	}
+
+	retryAfter, _ := store.TakeToken("a", limit)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	retryAfter, _ = store.TakeToken("b", limit)
+	assert.Zero(t, retryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+
+	retryAfter, _ = store.TakeToken("a", limit)
+	assert.Zero(t, retryAfter)
+
+	retryAfter, _ = store.TakeToken("a", limit)
+	assert.Positive(t, retryAfter)
+}
+
+func TestMemoryRateLimitStore_TakeWindow(t *testing.T) {
+	start := time.Now()
+	now := start
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	limits := []*WindowLimit{
+		{Limit: 1, Window: time.Minute},
+		{Limit: 3, Window: time.Hour},
+	}
+
+	retryAfter, err := store.TakeWindow("a", limits)
+	assert.NoError(t, err)
+	assert.Zero(t, retryAfter)
+
+	now = start.Add(10 * time.Second)
+	retryAfter, _ = store.TakeWindow("a", limits)
+	assert.Equal(t, 50*time.Second, retryAfter)
+
+	now = start.Add(time.Minute)
+	retryAfter, _ = store.TakeWindow("a", limits)
+	assert.Zero(t, retryAfter)
+
+	now = start.Add(2 * time.Minute)
+	retryAfter, _ = store.TakeWindow("a", limits)
+	assert.Zero(t, retryAfter)
+
+	now = start.Add(3 * time.Minute)
+	retryAfter, _ = store.TakeWindow("a", limits)
+	assert.Equal(t, 57*time.Minute, retryAfter)
+
+	now = start.Add(time.Hour)
+	retryAfter, _ = store.TakeWindow("a", limits)
+	assert.Zero(t, retryAfter)
+}
+
+type errRateLimitStore struct{}
+
+func (errRateLimitStore) TakeToken(key string, limit *RateLimit) (time.Duration, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func (errRateLimitStore) TakeWindow(key string, limits []*WindowLimit) (time.Duration, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func TestGsms_Send_RecipientRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &flakyGateway{name: "a"}
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"a"}),
+		WithRecipientRateLimit(&WindowLimit{Limit: 1, Window: time.Minute}),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	_, err = g.Send(18888888888, newAnyMessage(ctrl))
+
+	var rateLimited *ErrRateLimited
+	if assert.ErrorAs(t, err, &rateLimited) {
+		assert.Empty(t, rateLimited.Gateway)
+		assert.Equal(t, 18888888888, rateLimited.To.Number())
+		assert.Positive(t, rateLimited.RetryAfter)
+	}
+	assert.Equal(t, ErrorCodeRateLimited, ClassifyError(err))
+	assert.Equal(t, int32(1), gateway.calls)
+
+	_, err = g.Send(18800000000, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+}
+
+func TestGsms_Send_GatewayRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a := &flakyGateway{name: "a"}
+	b := &flakyGateway{name: "b"}
+
+	tracker := NewCircuitBreaker(1, time.Minute, 1)
+
+	g := New([]Gateway{a, b},
+		WithGateways([]string{"a", "b"}),
+		WithGatewayRateLimit("a", &RateLimit{QPS: 0.001}),
+		WithHealthTracker(tracker),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if assert.NoError(t, err) && assert.Len(t, results, 2) {
+		var rateLimited *ErrRateLimited
+		if assert.ErrorAs(t, results[0].Error, &rateLimited) {
+			assert.Equal(t, "a", rateLimited.Gateway)
+		}
+		assert.Equal(t, "b", results[1].Gateway)
+	}
+
+	assert.Equal(t, int32(1), a.calls)
+	assert.Equal(t, int32(1), b.calls)
+	assert.True(t, tracker.Healthy("a"))
+}
+
+func TestGsms_Send_GatewayRateLimit_All(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{&flakyGateway{name: "a"}, &flakyGateway{name: "b"}},
+		WithGateways([]string{"a", "b"}),
+		WithGatewayRateLimit("a", &RateLimit{QPS: 0.001}),
+		WithGatewayRateLimit("b", &RateLimit{QPS: 0.01}),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	_, err = g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+
+	// Every gateway is rate limited, the shortest wait is returned.
+	_, err = g.Send(18888888888, newAnyMessage(ctrl))
+
+	var rateLimited *ErrRateLimited
+	if assert.ErrorAs(t, err, &rateLimited) {
+		assert.Equal(t, "b", rateLimited.Gateway)
+		assert.Positive(t, rateLimited.RetryAfter)
+	}
+
+	var failed *ErrGatewaysFailed
+	if assert.ErrorAs(t, err, &failed) {
+		assert.Len(t, failed.Results, 2)
+	}
+}
+
+func TestErrGatewaysFailed_As(t *testing.T) {
+	var rateLimited *ErrRateLimited
+
+	err := NewErrGatewayFailed([]*Result{
+		{Gateway: "a", Error: NewErrRateLimited("a", nil, time.Second)},
+		{Gateway: "b", Error: errors.New("send failed")},
+	})
+	assert.False(t, errors.As(err, &rateLimited))
+
+	assert.False(t, errors.As(NewErrGatewayFailed(nil), &rateLimited))
+}
+
+func TestGsms_Send_RateLimitStoreFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{&flakyGateway{name: "a"}},
+		WithGateways([]string{"a"}),
+		WithRateLimitStore(errRateLimitStore{}),
+		WithGatewayRateLimit("a", &RateLimit{QPS: 1}),
+		WithRecipientRateLimit(&WindowLimit{Limit: 1, Window: time.Minute}),
+	)
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.NoError(t, err)
+}
+
+func TestGsms_SendBatch_RecipientRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batchGateway := &testBatchGateway{size: 10}
+
+	g := New([]Gateway{batchGateway},
+		WithGateways([]string{"batch"}),
+		WithRecipientRateLimit(&WindowLimit{Limit: 1, Window: time.Minute}),
+	)
+
+	_, err := g.Send(NewPhoneNumber(18800000001, "86"), newAnyMessage(ctrl))
+	assert.Error(t, err)
+
+	recipients := []*PhoneNumber{
+		NewPhoneNumber(18800000001, "86"),
+		NewPhoneNumber(18800000002, "86"),
+	}
+
+	batch, err := g.SendBatch(recipients, newAnyMessage(ctrl))
+
+	var failed *ErrBatchFailed
+	if assert.ErrorAs(t, err, &failed) && assert.Len(t, failed.Results, 1) {
+		var rateLimited *ErrRateLimited
+		assert.ErrorAs(t, failed.Results[0].Error, &rateLimited)
+	}
+
+	if assert.Len(t, batchGateway.chunks, 1) {
+		assert.Equal(t, []*PhoneNumber{recipients[1]}, batchGateway.chunks[0])
+	}
+	assert.Empty(t, batch[0].Results)
+	assert.Equal(t, StatusSuccess, batch[1].Status)
+}
@@ -0,0 +1,137 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy How a gateway is retried before failing over to the next one.
+// All attempts of a gateway share the Config.Timeout budget.
+type RetryPolicy struct {
+	// MaxAttempts Maximum attempts per gateway including the first one, values below 1 mean 1.
+	MaxAttempts int
+	// BaseBackoff Backoff before the first retry, doubled for each following retry.
+	BaseBackoff time.Duration
+	// MaxBackoff Upper limit of the backoff, zero means no limit.
+	MaxBackoff time.Duration
+	// Jitter Fraction in [0, 1] of the backoff that is randomized.
+	Jitter float64
+	// RetryableCodes Error codes to retry, defaults to the codes of SendError.Retryable.
+	RetryableCodes []ErrorCode
+}
+
+// defaultRetryPolicy No retry.
+var defaultRetryPolicy = &RetryPolicy{MaxAttempts: 1}
+
+// maxAttempts Maximum attempts per gateway.
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// retryable Whether the error is worth retrying.
+func (p *RetryPolicy) retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	code := ClassifyError(err)
+
+	if p.RetryableCodes == nil {
+		return (&SendError{Code: code}).Retryable()
+	}
+
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff Backoff before the retry following the attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.BaseBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+
+		backoff -= time.Duration(float64(backoff) * jitter * rand.Float64())
+	}
+
+	return backoff
+}
+
+// retryPolicy Get the retry policy of the gateway.
+func (g *Gsms) retryPolicy(gateway string) *RetryPolicy {
+	if policy, ok := g.gatewayRetryPolicies[gateway]; ok && policy != nil {
+		return policy
+	}
+
+	if g.defaultRetryPolicy != nil {
+		return g.defaultRetryPolicy
+	}
+
+	return defaultRetryPolicy
+}
+
+// attemptWithRetry Send the message via the gateway, retrying according to the retry policy.
+// Every attempt is returned, the last one decides whether the gateway succeeded.
+func (g *Gsms) attemptWithRetry(ctx context.Context, s *sending, gateway string) []*Result {
+	policy := g.retryPolicy(gateway)
+
+	if g.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Timeout)
+		defer cancel()
+	}
+
+	var results []*Result
+
+	for attempt := 1; ; attempt++ {
+		result := g.attempt(ctx, s, gateway, attempt)
+		results = append(results, result)
+
+		if result.Status == StatusSuccess || attempt >= policy.maxAttempts() || !policy.retryable(result.Error) {
+			return results
+		}
+
+		backoff := policy.backoff(attempt)
+
+		var rateLimitedErr *ErrRateLimited
+		if errors.As(result.Error, &rateLimitedErr) && rateLimitedErr.RetryAfter > backoff {
+			backoff = rateLimitedErr.RetryAfter
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= backoff {
+			return results
+		}
+
+		g.config.Logger.Infof("[%s] retry in %s after attempt %d failed", gateway, backoff, attempt)
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return results
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package gsms
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type flakyGateway struct {
+	name     string
+	failures int32
+	err      error
+	calls    int32
+}
+
+func (f *flakyGateway) Name() string {
+	return f.name
+}
+
+func (f *flakyGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return f.err
+	}
+
+	return nil
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := &RetryPolicy{BaseBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 300*time.Millisecond, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		backoff := policy.backoff(1)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 100*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_retryable(t *testing.T) {
+	policy := &RetryPolicy{}
+	assert.True(t, policy.retryable(NewSendError("a", ErrorCodeTransient, "", "")))
+	assert.False(t, policy.retryable(NewSendError("a", ErrorCodeAuthFailed, "", "")))
+	assert.False(t, policy.retryable(nil))
+
+	policy.RetryableCodes = []ErrorCode{ErrorCodeAuthFailed}
+	assert.False(t, policy.retryable(NewSendError("a", ErrorCodeTransient, "", "")))
+	assert.True(t, policy.retryable(NewSendError("a", ErrorCodeAuthFailed, "", "")))
+}
+
+func TestGsms_Send_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &flakyGateway{name: "a", failures: 2, err: context.DeadlineExceeded}
+
+	g := New([]Gateway{gateway, &flakyGateway{name: "b"}},
+		WithGateways([]string{"a", "b"}),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}),
+	)
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 3)
+	for i, result := range results {
+		assert.Equal(t, "a", result.Gateway)
+		assert.Equal(t, i+1, result.Attempt)
+	}
+	assert.Equal(t, StatusSuccess, results[2].Status)
+}
+
+func TestGsms_Send_Retry_NotRetryable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &flakyGateway{name: "a", failures: 1, err: NewSendError("a", ErrorCodeAuthFailed, "", "")}
+
+	g := New([]Gateway{gateway, &flakyGateway{name: "b"}},
+		WithGateways([]string{"a", "b"}),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}),
+	)
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Gateway)
+	assert.Equal(t, "b", results[1].Gateway)
+}
+
+func TestGsms_Send_GatewayRetryPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &flakyGateway{name: "a", failures: 1, err: context.DeadlineExceeded}
+
+	g := New([]Gateway{gateway, &flakyGateway{name: "b"}},
+		WithGateways([]string{"a", "b"}),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseBackoff: time.Millisecond}),
+		WithGatewayRetryPolicy("a", &RetryPolicy{MaxAttempts: 1}),
+	)
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "b", results[1].Gateway)
+}
+
+func TestGsms_Send_Retry_Budget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &flakyGateway{name: "a", failures: 10, err: context.DeadlineExceeded}
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"a"}),
+		WithTimeout(50*time.Millisecond),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 10, BaseBackoff: 20 * time.Millisecond}),
+	)
+
+	start := time.Now()
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Less(t, atomic.LoadInt32(&gateway.calls), int32(10))
+}
@@ -0,0 +1,77 @@
+package routing
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/utils"
+	"sort"
+)
+
+var _ gsms.RoutingStrategy = (*CostStrategy)(nil)
+var _ gsms.CostEstimator = (*CostStrategy)(nil)
+
+// CostStrategy Order gateways cheapest first for the recipient.
+// The cost is the price per segment multiplied by the segment count of the message content,
+// gateways without a price are tried last in their original order.
+type CostStrategy struct {
+	Prices *PriceTable
+	// Segments Count the segments of the message, defaults to the segments of the message content.
+	// Template messages without content count as one segment.
+	Segments func(gateway gsms.Gateway, message gsms.Message) int
+}
+
+// Apply Order gateways by the domestic cost, used when the recipient is unknown.
+func (c *CostStrategy) Apply(gateways []string) []string {
+	return c.Route(gsms.NewPhoneNumberWithoutIDDCode(0), nil, gateways)
+}
+
+// Route Order the gateways cheapest first for the recipient.
+func (c *CostStrategy) Route(to *gsms.PhoneNumber, message gsms.Message, gateways []string) []string {
+	result := copyGateways(gateways)
+
+	costs := make(map[string]float64, len(result))
+	priced := make(map[string]bool, len(result))
+
+	for _, gateway := range result {
+		costs[gateway], priced[gateway] = c.EstimateCost(gsms.NamedGateway(gateway), to, message)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if priced[result[i]] != priced[result[j]] {
+			return priced[result[i]]
+		}
+
+		return costs[result[i]] < costs[result[j]]
+	})
+
+	return result
+}
+
+// EstimateCost Estimated cost of sending the message to the recipient via the gateway, false if unknown.
+func (c *CostStrategy) EstimateCost(gateway gsms.Gateway, to *gsms.PhoneNumber, message gsms.Message) (float64, bool) {
+	price, ok := c.Prices.Price(gateway.Name(), to.IDDCode())
+	if !ok {
+		return 0, false
+	}
+
+	return price * float64(c.segments(gateway, message)), true
+}
+
+// segments Segment count of the message, at least 1.
+func (c *CostStrategy) segments(gateway gsms.Gateway, message gsms.Message) int {
+	if message == nil {
+		return 1
+	}
+
+	var segments int
+	if c.Segments != nil {
+		segments = c.Segments(gateway, message)
+	} else if content, err := message.GetContent(gateway); err == nil {
+		segments = utils.SegmentCount(content)
+	}
+
+	if segments < 1 {
+		return 1
+	}
+
+	return segments
+}
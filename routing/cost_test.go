@@ -0,0 +1,62 @@
+package routing
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCostStrategy_Route(t *testing.T) {
+	s := &CostStrategy{
+		Prices: &PriceTable{
+			Gateways: map[string]*GatewayPrices{
+				"aliyun": {Default: 0.35, Countries: map[int]float64{86: 0.045}},
+				"qcloud": {Countries: map[int]float64{86: 0.04, 852: 0.3}},
+			},
+		},
+	}
+
+	msg := &message.Message{Content: "【Gsms】您的验证码是521410"}
+	gateways := []string{"yunpian", "aliyun", "qcloud"}
+
+	assert.Equal(t, []string{"qcloud", "aliyun", "yunpian"}, s.Route(gsms.NewPhoneNumber(18888888888, "86"), msg, gateways))
+	assert.Equal(t, []string{"qcloud", "aliyun", "yunpian"}, s.Route(gsms.NewPhoneNumber(61234567, "852"), msg, gateways))
+	assert.Equal(t, []string{"aliyun", "yunpian", "qcloud"}, s.Route(gsms.NewPhoneNumber(2025550123, "1"), msg, gateways))
+	assert.Equal(t, []string{"qcloud", "aliyun", "yunpian"}, s.Apply(gateways))
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, gateways)
+}
+
+func TestCostStrategy_Route_Segments(t *testing.T) {
+	s := &CostStrategy{
+		Prices: &PriceTable{
+			Gateways: map[string]*GatewayPrices{
+				"aliyun": {Countries: map[int]float64{86: 0.05}},
+				"qcloud": {Countries: map[int]float64{86: 0.04}},
+			},
+		},
+	}
+
+	// qcloud has a longer content and costs 2 segments.
+	msg := &message.Message{
+		Content: func(gateway gsms.Gateway) string {
+			if gateway.Name() == "qcloud" {
+				return strings.Repeat("验", 71)
+			}
+			return "验证码"
+		},
+	}
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+
+	assert.Equal(t, []string{"aliyun", "qcloud"}, s.Route(to, msg, []string{"qcloud", "aliyun"}))
+
+	cost, ok := s.EstimateCost(gsms.NamedGateway("qcloud"), to, msg)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.08, cost, 1e-9)
+
+	cost, ok = s.EstimateCost(gsms.NamedGateway("aliyun"), to, &message.Message{Template: "SMS_00000001"})
+	assert.True(t, ok)
+	assert.InDelta(t, 0.05, cost, 1e-9)
+}
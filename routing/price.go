@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DomesticIDDCode IDD code used to price numbers without IDD code.
+const DomesticIDDCode = 86
+
+// PriceTable Price per segment by gateway and IDD code.
+//
+//	currency: CNY
+//	gateways:
+//	  aliyun:
+//	    default: 0.35
+//	    countries:
+//	      86: 0.045
+//	      852: 0.32
+type PriceTable struct {
+	Currency string                    `json:"currency" yaml:"currency"`
+	Gateways map[string]*GatewayPrices `json:"gateways" yaml:"gateways"`
+}
+
+// GatewayPrices Prices per segment of a gateway.
+type GatewayPrices struct {
+	// Default Price of countries not listed, 0 means unknown.
+	Default float64 `json:"default" yaml:"default"`
+	// Countries Price by IDD code.
+	Countries map[int]float64 `json:"countries" yaml:"countries"`
+}
+
+// Price Price per segment of the gateway for the IDD code, false if unknown.
+func (p *PriceTable) Price(gateway string, iddCode int) (float64, bool) {
+	if p == nil {
+		return 0, false
+	}
+
+	prices, ok := p.Gateways[gateway]
+	if !ok || prices == nil {
+		return 0, false
+	}
+
+	if iddCode == 0 {
+		iddCode = DomesticIDDCode
+	}
+
+	if price, ok := prices.Countries[iddCode]; ok {
+		return price, true
+	}
+
+	if prices.Default > 0 {
+		return prices.Default, true
+	}
+
+	return 0, false
+}
+
+// ParsePriceTableJSON Parse a price table from JSON.
+func ParsePriceTableJSON(data []byte) (*PriceTable, error) {
+	var table PriceTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+// ParsePriceTableYAML Parse a price table from YAML.
+func ParsePriceTableYAML(data []byte) (*PriceTable, error) {
+	var table PriceTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+// LoadPriceTable Load a price table from a .json, .yaml or .yml file.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ParsePriceTableJSON(data)
+	case ".yaml", ".yml":
+		return ParsePriceTableYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported price table format: %s", path)
+	}
+}
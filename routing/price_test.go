@@ -0,0 +1,42 @@
+package routing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadPriceTable(t *testing.T) {
+	for _, path := range []string{"testdata/prices.yaml", "testdata/prices.json"} {
+		t.Run(path, func(t *testing.T) {
+			table, err := LoadPriceTable(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, "CNY", table.Currency)
+
+			price, ok := table.Price("aliyun", 86)
+			assert.True(t, ok)
+			assert.Equal(t, 0.045, price)
+
+			price, ok = table.Price("aliyun", 1)
+			assert.True(t, ok)
+			assert.Equal(t, 0.35, price)
+
+			price, ok = table.Price("qcloud", 0)
+			assert.True(t, ok)
+			assert.Equal(t, 0.04, price)
+
+			_, ok = table.Price("qcloud", 1)
+			assert.False(t, ok)
+
+			_, ok = table.Price("yunpian", 86)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestLoadPriceTable_Unsupported(t *testing.T) {
+	_, err := LoadPriceTable("testdata/prices.toml")
+	assert.Error(t, err)
+}
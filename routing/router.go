@@ -0,0 +1,134 @@
+package routing
+
+import (
+	"github.com/maiqingqiang/gsms"
+)
+
+const (
+	// RegionChineseMainland Numbers in chinese mainland, numbers without IDD code are treated as domestic.
+	RegionChineseMainland = "mainland"
+	// RegionInternational Numbers outside chinese mainland.
+	RegionInternational = "international"
+)
+
+var _ gsms.RoutingStrategy = (*Router)(nil)
+
+// Rule Route recipients matching any of the IDD codes, regions or the Match func to the gateways.
+type Rule struct {
+	// IDDCodes e.g. 852, 853.
+	IDDCodes []int
+	// Regions Built-in regions or regions defined in Router.Regions.
+	Regions []string
+	// Match Custom matcher, e.g. by message type.
+	Match func(to *gsms.PhoneNumber, message gsms.Message) bool
+	// Gateways Gateways to try in order.
+	Gateways []string
+}
+
+// Router Route recipients to gateways by rules, the first matching rule wins.
+// Gateways of the rule are limited to the gateways of the message (or gsms.WithGateways) if there are any,
+// the Default chain is used if no rule matches or none of the rule gateways is available,
+// and the gateways are kept in their order if the Default chain is not available either.
+type Router struct {
+	Rules []*Rule
+	// Default Fallback gateways chain.
+	Default []string
+	// Regions Custom regions by IDD codes, e.g. "HMT": {852, 853, 886}.
+	Regions map[string][]int
+}
+
+// Apply Route without a recipient, the Default chain is used.
+func (r *Router) Apply(gateways []string) []string {
+	if chain := available(r.Default, gateways); len(chain) > 0 {
+		return chain
+	}
+
+	return copyGateways(gateways)
+}
+
+// Route Order the gateways for the recipient and message.
+func (r *Router) Route(to *gsms.PhoneNumber, message gsms.Message, gateways []string) []string {
+	for _, rule := range r.Rules {
+		if !r.match(rule, to, message) {
+			continue
+		}
+
+		if chain := available(rule.Gateways, gateways); len(chain) > 0 {
+			return chain
+		}
+
+		break
+	}
+
+	return r.Apply(gateways)
+}
+
+// match Whether the recipient matches the rule.
+func (r *Router) match(rule *Rule, to *gsms.PhoneNumber, message gsms.Message) bool {
+	if to == nil {
+		return false
+	}
+
+	iddCode := to.IDDCode()
+
+	for _, code := range rule.IDDCodes {
+		if code == iddCode {
+			return true
+		}
+	}
+
+	for _, region := range rule.Regions {
+		if r.inRegion(region, iddCode) {
+			return true
+		}
+	}
+
+	return rule.Match != nil && rule.Match(to, message)
+}
+
+// inRegion Whether the IDD code belongs to the region.
+func (r *Router) inRegion(region string, iddCode int) bool {
+	if codes, ok := r.Regions[region]; ok {
+		for _, code := range codes {
+			if code == iddCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch region {
+	case RegionChineseMainland:
+		return iddCode == 0 || iddCode == 86
+	case RegionInternational:
+		return iddCode != 0 && iddCode != 86
+	}
+
+	return false
+}
+
+// available Keep the chain gateways that are in the candidates, all of them if there are no candidates.
+func available(chain []string, candidates []string) []string {
+	if len(candidates) == 0 {
+		return copyGateways(chain)
+	}
+
+	result := make([]string, 0, len(chain))
+	for _, gateway := range chain {
+		for _, candidate := range candidates {
+			if gateway == candidate {
+				result = append(result, gateway)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// copyGateways Copy the gateways so callers can not mutate the configuration.
+func copyGateways(gateways []string) []string {
+	result := make([]string, len(gateways))
+	copy(result, gateways)
+	return result
+}
@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRouter_Route(t *testing.T) {
+	r := &Router{
+		Rules: []*Rule{
+			{IDDCodes: []int{852}, Gateways: []string{"yunpian"}},
+			{Regions: []string{RegionChineseMainland}, Gateways: []string{"aliyun", "qcloud"}},
+			{Regions: []string{RegionInternational}, Gateways: []string{"qcloud", "yunpian"}},
+		},
+		Default: []string{"qcloud"},
+	}
+
+	msg := &message.Message{Content: "【Gsms】您的验证码是521410"}
+
+	tests := []struct {
+		name       string
+		to         *gsms.PhoneNumber
+		candidates []string
+		want       []string
+	}{
+		{
+			name: "mainland",
+			to:   gsms.NewPhoneNumber(18888888888, "86"),
+			want: []string{"aliyun", "qcloud"},
+		},
+		{
+			name: "without IDD code",
+			to:   gsms.NewPhoneNumberWithoutIDDCode(18888888888),
+			want: []string{"aliyun", "qcloud"},
+		},
+		{
+			name: "IDD code wins by order",
+			to:   gsms.NewPhoneNumber(61234567, "852"),
+			want: []string{"yunpian"},
+		},
+		{
+			name: "international",
+			to:   gsms.NewPhoneNumber(2025550123, "1"),
+			want: []string{"qcloud", "yunpian"},
+		},
+		{
+			name:       "limited to candidates",
+			to:         gsms.NewPhoneNumber(18888888888, "86"),
+			candidates: []string{"qcloud", "yunpian"},
+			want:       []string{"qcloud"},
+		},
+		{
+			name:       "fallback to default",
+			to:         gsms.NewPhoneNumber(61234567, "852"),
+			candidates: []string{"aliyun", "qcloud"},
+			want:       []string{"qcloud"},
+		},
+		{
+			name:       "fallback to candidates",
+			to:         gsms.NewPhoneNumber(61234567, "852"),
+			candidates: []string{"aliyun"},
+			want:       []string{"aliyun"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Route(tt.to, msg, tt.candidates))
+		})
+	}
+}
+
+func TestRouter_Route_Regions(t *testing.T) {
+	r := &Router{
+		Rules: []*Rule{
+			{Regions: []string{"HMT"}, Gateways: []string{"yunpian"}},
+			{
+				Match: func(to *gsms.PhoneNumber, message gsms.Message) bool {
+					return to.Number() == 18888888888
+				},
+				Gateways: []string{"aliyun"},
+			},
+		},
+		Regions: map[string][]int{"HMT": {852, 853, 886}},
+	}
+
+	assert.Equal(t, []string{"yunpian"}, r.Route(gsms.NewPhoneNumber(61234567, "853"), nil, nil))
+	assert.Equal(t, []string{"aliyun"}, r.Route(gsms.NewPhoneNumber(18888888888, "86"), nil, nil))
+	assert.Equal(t, []string{"qcloud"}, r.Route(gsms.NewPhoneNumber(18800000000, "86"), nil, []string{"qcloud"}))
+}
+
+func TestRouter_Apply(t *testing.T) {
+	r := &Router{Default: []string{"qcloud", "aliyun"}}
+
+	assert.Equal(t, []string{"qcloud", "aliyun"}, r.Apply([]string{"aliyun", "qcloud", "yunpian"}))
+	assert.Equal(t, []string{"yunpian"}, r.Apply([]string{"yunpian"}))
+}
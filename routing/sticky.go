@@ -0,0 +1,131 @@
+package routing
+
+import (
+	"github.com/maiqingqiang/gsms"
+	"time"
+)
+
+// DefaultStickyTTL Default time a recipient sticks to the gateway.
+const DefaultStickyTTL = 24 * time.Hour
+
+var _ gsms.RoutingFeedbackStrategy = (*StickyStrategy)(nil)
+var _ gsms.FeedbackStrategy = (*StickyStrategy)(nil)
+var _ gsms.CostEstimator = (*StickyStrategy)(nil)
+
+// StickyStore Store the last successful gateway by recipient.
+type StickyStore interface {
+	// Get Get the gateway of the key, empty if there is none.
+	Get(key string) (string, error)
+	// Set Set the gateway of the key, it expires after the ttl.
+	Set(key string, gateway string, ttl time.Duration) error
+	// Delete Delete the key.
+	Delete(key string) error
+}
+
+// StickyStrategy Try the gateway that last succeeded for the recipient first, so resends come from the same sender.
+// The other gateways are ordered by the inner strategy, the recipient is keyed by PhoneNumber.UniversalNumber().
+// The recipient is forgotten once its gateway fails. Store errors are ignored, the inner order is used instead.
+type StickyStrategy struct {
+	// Strategy Inner strategy, the gateways order is kept if nil.
+	Strategy gsms.Strategy
+	Store    StickyStore
+	// TTL Time a recipient sticks to the gateway, defaults to DefaultStickyTTL.
+	TTL time.Duration
+}
+
+// NewStickyStrategy New a sticky strategy with an in-memory store.
+func NewStickyStrategy(strategy gsms.Strategy, ttl time.Duration) *StickyStrategy {
+	return &StickyStrategy{
+		Strategy: strategy,
+		Store:    NewMemoryStickyStore(DefaultStickyCapacity),
+		TTL:      ttl,
+	}
+}
+
+// Apply Apply the inner strategy, used when the recipient is unknown.
+func (s *StickyStrategy) Apply(gateways []string) []string {
+	if s.Strategy == nil {
+		return copyGateways(gateways)
+	}
+
+	return s.Strategy.Apply(gateways)
+}
+
+// Route Move the sticky gateway of the recipient to the front.
+func (s *StickyStrategy) Route(to *gsms.PhoneNumber, message gsms.Message, gateways []string) []string {
+	var result []string
+	if strategy, ok := s.Strategy.(gsms.RoutingStrategy); ok {
+		result = strategy.Route(to, message, gateways)
+	} else {
+		result = s.Apply(gateways)
+	}
+
+	if to == nil || s.Store == nil {
+		return result
+	}
+
+	sticky, err := s.Store.Get(to.UniversalNumber())
+	if err != nil || sticky == "" {
+		return result
+	}
+
+	// The inner strategy may return the caller's slice.
+	result = copyGateways(result)
+
+	for i, gateway := range result {
+		if gateway == sticky {
+			copy(result[1:i+1], result[:i])
+			result[0] = sticky
+			break
+		}
+	}
+
+	return result
+}
+
+// ObserveRoute Remember the gateway on success, forget it on failure.
+func (s *StickyStrategy) ObserveRoute(to *gsms.PhoneNumber, gateway string, latency time.Duration, err error) {
+	if strategy, ok := s.Strategy.(gsms.RoutingFeedbackStrategy); ok {
+		strategy.ObserveRoute(to, gateway, latency, err)
+	}
+
+	if to == nil || s.Store == nil {
+		return
+	}
+
+	key := to.UniversalNumber()
+
+	if err == nil {
+		_ = s.Store.Set(key, gateway, s.ttl())
+		return
+	}
+
+	if sticky, _ := s.Store.Get(key); sticky == gateway {
+		_ = s.Store.Delete(key)
+	}
+}
+
+// Observe Forward the outcome to the inner strategy.
+func (s *StickyStrategy) Observe(gateway string, latency time.Duration, err error) {
+	if strategy, ok := s.Strategy.(gsms.FeedbackStrategy); ok {
+		strategy.Observe(gateway, latency, err)
+	}
+}
+
+// EstimateCost Forward to the inner strategy, false if it is not a gsms.CostEstimator.
+func (s *StickyStrategy) EstimateCost(gateway gsms.Gateway, to *gsms.PhoneNumber, message gsms.Message) (float64, bool) {
+	if estimator, ok := s.Strategy.(gsms.CostEstimator); ok {
+		return estimator.EstimateCost(gateway, to, message)
+	}
+
+	return 0, false
+}
+
+// ttl TTL, defaults to DefaultStickyTTL.
+func (s *StickyStrategy) ttl() time.Duration {
+	if s.TTL <= 0 {
+		return DefaultStickyTTL
+	}
+
+	return s.TTL
+}
@@ -0,0 +1,111 @@
+package routing
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultStickyCapacity Default number of recipients kept by the in-memory sticky store.
+const DefaultStickyCapacity = 10000
+
+var _ StickyStore = (*MemoryStickyStore)(nil)
+
+// MemoryStickyStore In-memory LRU sticky store, the least recently used recipient is evicted when it is full.
+type MemoryStickyStore struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+// stickyEntry Entry of the MemoryStickyStore.
+type stickyEntry struct {
+	key       string
+	gateway   string
+	expiresAt time.Time
+}
+
+// NewMemoryStickyStore New an in-memory sticky store, capacity <= 0 means unlimited.
+func NewMemoryStickyStore(capacity int) *MemoryStickyStore {
+	return &MemoryStickyStore{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+		now:      time.Now,
+	}
+}
+
+func (m *MemoryStickyStore) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return "", nil
+	}
+
+	entry := element.Value.(*stickyEntry)
+	if !m.now().Before(entry.expiresAt) {
+		m.remove(element)
+		return "", nil
+	}
+
+	m.lru.MoveToFront(element)
+
+	return entry.gateway, nil
+}
+
+func (m *MemoryStickyStore) Set(key string, gateway string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiresAt := m.now().Add(ttl)
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*stickyEntry)
+		entry.gateway = gateway
+		entry.expiresAt = expiresAt
+		m.lru.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.lru.PushFront(&stickyEntry{
+		key:       key,
+		gateway:   gateway,
+		expiresAt: expiresAt,
+	})
+
+	if m.capacity > 0 && m.lru.Len() > m.capacity {
+		m.remove(m.lru.Back())
+	}
+
+	return nil
+}
+
+func (m *MemoryStickyStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+// Len Number of recipients in the store, including expired ones not evicted yet.
+func (m *MemoryStickyStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.lru.Len()
+}
+
+// remove Remove the element.
+func (m *MemoryStickyStore) remove(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.entries, element.Value.(*stickyEntry).key)
+}
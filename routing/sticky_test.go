@@ -0,0 +1,127 @@
+package routing
+
+import (
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/maiqingqiang/gsms/strategies"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testGateway struct {
+	name   string
+	failed bool
+	sent   int
+}
+
+func (t *testGateway) Name() string {
+	return t.name
+}
+
+func (t *testGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	t.sent++
+	if t.failed {
+		return errors.New("send failed")
+	}
+	return nil
+}
+
+func TestMemoryStickyStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStickyStore(2)
+	store.now = func() time.Time { return now }
+
+	_ = store.Set("a", "aliyun", time.Minute)
+	_ = store.Set("b", "qcloud", time.Minute)
+
+	gateway, _ := store.Get("a")
+	assert.Equal(t, "aliyun", gateway)
+
+	// b is the least recently used.
+	_ = store.Set("c", "yunpian", time.Minute)
+	assert.Equal(t, 2, store.Len())
+
+	gateway, _ = store.Get("b")
+	assert.Empty(t, gateway)
+
+	now = now.Add(time.Minute)
+
+	gateway, _ = store.Get("a")
+	assert.Empty(t, gateway)
+	assert.Equal(t, 1, store.Len())
+
+	_ = store.Delete("c")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestStickyStrategy_Route(t *testing.T) {
+	s := NewStickyStrategy(&strategies.OrderStrategy{}, time.Minute)
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+	other := gsms.NewPhoneNumber(18800000000, "86")
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, s.Route(to, nil, gateways))
+
+	s.ObserveRoute(to, "yunpian", time.Millisecond, nil)
+
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, s.Route(to, nil, gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, s.Route(other, nil, gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud"}, s.Route(to, nil, []string{"aliyun", "qcloud"}))
+
+	s.ObserveRoute(to, "aliyun", time.Millisecond, errors.New("send failed"))
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, s.Route(to, nil, gateways))
+
+	s.ObserveRoute(to, "yunpian", time.Millisecond, errors.New("send failed"))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, s.Route(to, nil, gateways))
+}
+
+// identityStrategy Strategy returning the caller's slice.
+type identityStrategy struct{}
+
+func (identityStrategy) Apply(gateways []string) []string {
+	return gateways
+}
+
+func TestStickyStrategy_Route_Copy(t *testing.T) {
+	s := NewStickyStrategy(identityStrategy{}, time.Minute)
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	s.ObserveRoute(to, "yunpian", time.Millisecond, nil)
+
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, s.Route(to, nil, gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, gateways)
+}
+
+func TestStickyStrategy_Send(t *testing.T) {
+	aliyun := &testGateway{name: "aliyun", failed: true}
+	qcloud := &testGateway{name: "qcloud"}
+
+	client := gsms.New(
+		[]gsms.Gateway{aliyun, qcloud},
+		gsms.WithGateways([]string{"aliyun", "qcloud"}),
+		gsms.WithStrategy(NewStickyStrategy(&strategies.OrderStrategy{}, time.Minute)),
+	)
+
+	to := gsms.NewPhoneNumber(18888888888, "86")
+	msg := &message.Message{Content: "【Gsms】您的验证码是521410"}
+
+	results, err := client.Send(to, msg)
+	if assert.NoError(t, err) {
+		assert.Len(t, results, 2)
+	}
+
+	aliyun.failed = false
+
+	results, err = client.Send(to, msg)
+	if assert.NoError(t, err) && assert.Len(t, results, 1) {
+		assert.Equal(t, "qcloud", results[0].Gateway)
+	}
+
+	assert.Equal(t, 1, aliyun.sent)
+	assert.Equal(t, 2, qcloud.sent)
+}
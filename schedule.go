@@ -0,0 +1,456 @@
+package gsms
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultScheduleTick Tick of the in-memory timer wheel and interval of polling the schedule store.
+	DefaultScheduleTick = 100 * time.Millisecond
+	// DefaultScheduleSlots Number of slots of the in-memory timer wheel.
+	DefaultScheduleSlots = 512
+)
+
+// ScheduledSend A send scheduled by gsms.
+type ScheduledSend struct {
+	ID       string
+	At       time.Time
+	To       *PhoneNumber
+	Message  Message
+	Gateways []string
+	// Location Time zone of the recipient, nil to derive it from the IDD code, see ContextWithLocation.
+	Location *time.Location
+	// IdempotencyKey Idempotency key of the send, empty if it has none, see ContextWithIdempotencyKey.
+	IdempotencyKey string
+}
+
+// ScheduleHandle Handle of a scheduled send.
+type ScheduleHandle struct {
+	ID string
+	At time.Time
+	// Results Results of scheduling at the providers, nil if the send is scheduled by gsms.
+	Results []*Result
+
+	gsms *Gsms
+}
+
+// Native Whether the send is scheduled at the provider.
+func (h *ScheduleHandle) Native() bool {
+	return h.Results != nil
+}
+
+// Cancel the scheduled send, ErrScheduleNotFound if it was already sent or cancelled.
+func (h *ScheduleHandle) Cancel() error {
+	return h.CancelContext(context.Background())
+}
+
+// CancelContext Cancel the scheduled send with context.
+func (h *ScheduleHandle) CancelContext(ctx context.Context) error {
+	if !h.Native() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return h.gsms.scheduler.store.Remove(h.ID)
+	}
+
+	result := h.Results[len(h.Results)-1]
+
+	gateway, err := h.gsms.Gateway(result.Gateway)
+	if err != nil {
+		return err
+	}
+
+	gw, ok := gateway.(ScheduleGateway)
+	if !ok {
+		return ErrScheduleUnsupported
+	}
+
+	return gw.CancelScheduled(ctx, result.Receipt, h.gsms.config)
+}
+
+// Schedule Send a message at the time.
+func (g *Gsms) Schedule(at time.Time, to interface{}, message Message, gateways ...string) (*ScheduleHandle, error) {
+	return g.ScheduleContext(context.Background(), at, to, message, gateways...)
+}
+
+// ScheduleContext Send a message at the time with context.
+// If every gateway implements ScheduleGateway and the time is in the send window, the message is scheduled at the
+// first provider accepting it. Otherwise the send is kept in the schedule store and sent by gsms once it is due,
+// never before the time, only the location and the idempotency key of the context are kept for the send.
+func (g *Gsms) ScheduleContext(ctx context.Context, at time.Time, to interface{}, message Message, gateways ...string) (*ScheduleHandle, error) {
+	phoneNumber, err := parsePhoneNumber(to)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := g.candidateGateways(message, gateways)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.schedulesNatively(candidates) && at.After(time.Now()) {
+		if _, ok := g.nextSendWindow(ctx, phoneNumber, message, at); ok {
+			return g.scheduleNatively(ctx, at, phoneNumber, message, candidates)
+		}
+	}
+
+	send := &ScheduledSend{
+		ID:       uuid.New().String(),
+		At:       at,
+		To:       phoneNumber,
+		Message:  message,
+		Gateways: gateways,
+	}
+
+	if location, ok := LocationFromContext(ctx); ok {
+		send.Location = location
+	}
+
+	if key, ok := IdempotencyKeyFromContext(ctx); ok {
+		send.IdempotencyKey = key
+	}
+
+	if err := g.scheduler.add(g, send); err != nil {
+		return nil, err
+	}
+
+	return &ScheduleHandle{ID: send.ID, At: at, gsms: g}, nil
+}
+
+// Close Stop sending the scheduled sends and wait for the ones in flight, the pending ones are kept in the store.
+func (g *Gsms) Close() error {
+	g.scheduler.close()
+	return nil
+}
+
+// schedulesNatively Whether every gateway supports scheduling at the provider.
+func (g *Gsms) schedulesNatively(gateways []string) bool {
+	for _, name := range gateways {
+		if _, ok := g.gateways[name].(ScheduleGateway); !ok {
+			return false
+		}
+	}
+
+	return len(gateways) > 0
+}
+
+// scheduleNatively Schedule the message at the providers in the order of the strategy until one of them accepts it.
+// The provider sends the message, so the hooks, middlewares and retries do not apply.
+func (g *Gsms) scheduleNatively(ctx context.Context, at time.Time, to *PhoneNumber, message Message, gateways []string) (*ScheduleHandle, error) {
+	if err := g.checkSuppression(to, message); err != nil {
+		return nil, err
+	}
+
+	s := &sending{
+		to:       to,
+		message:  message,
+		strategy: g.strategyOf(message),
+	}
+
+	var results []*Result
+
+	for _, name := range g.orderGateways(s, gateways) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		gateway := g.gateways[name].(ScheduleGateway)
+		result := &Result{Gateway: name, Status: StatusSuccess, Attempt: 1}
+
+		if template, err := message.GetTemplate(gateway); err == nil {
+			result.Template = template
+		}
+
+		start := time.Now()
+		result.Receipt, result.Error = gateway.SendAt(ctx, to, message, at, g.config)
+		result.Latency = time.Since(start)
+
+		results = append(results, result)
+
+		if result.Error == nil {
+			return &ScheduleHandle{ID: uuid.New().String(), At: at, Results: results, gsms: g}, nil
+		}
+
+		result.Status = StatusFailure
+		g.config.Logger.Warnf("[%s] schedule at gateway %s failed: %+v", to, name, result.Error)
+
+		if isRecipientError(result.Error) {
+			break
+		}
+	}
+
+	return nil, NewErrGatewayFailed(results)
+}
+
+// sendScheduled Send a due scheduled send.
+func (g *Gsms) sendScheduled(send *ScheduledSend) {
+	ctx := context.Background()
+	if send.Location != nil {
+		ctx = ContextWithLocation(ctx, send.Location)
+	}
+
+	if send.IdempotencyKey != "" {
+		ctx = ContextWithIdempotencyKey(ctx, send.IdempotencyKey)
+	}
+
+	results, err := g.SendContext(ctx, send.To, send.Message, send.Gateways...)
+	if err != nil {
+		g.config.Logger.Warnf("[%s] send scheduled message failed: %+v", send.ID, err)
+	}
+
+	if g.scheduler.callback != nil {
+		g.scheduler.callback(send, results, err)
+	}
+}
+
+// scheduler Poll the schedule store and send the due sends, shared by the copies of gsms.
+// It starts with the first scheduled send.
+type scheduler struct {
+	store    ScheduleStore
+	interval time.Duration
+	callback func(send *ScheduledSend, results []*Result, err error)
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newScheduler New a scheduler with an in-memory timer wheel.
+func newScheduler() *scheduler {
+	return &scheduler{
+		store:    NewMemoryScheduleStore(DefaultScheduleTick),
+		interval: DefaultScheduleTick,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// add Add the send to the store and start polling.
+func (s *scheduler) add(g *Gsms, send *ScheduledSend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSchedulerClosed
+	}
+
+	if err := s.store.Add(send); err != nil {
+		return err
+	}
+
+	if !s.started {
+		s.started = true
+		go s.run(g)
+	}
+
+	return nil
+}
+
+// run Poll the store until the scheduler is closed.
+func (s *scheduler) run(g *Gsms) {
+	var wg sync.WaitGroup
+
+	defer close(s.done)
+	defer wg.Wait()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			due, err := s.store.Due(now)
+			if err != nil {
+				g.config.Logger.Errorf("get due scheduled sends failed: %+v", err)
+				continue
+			}
+
+			for _, send := range due {
+				wg.Add(1)
+				go func(send *ScheduledSend) {
+					defer wg.Done()
+					g.sendScheduled(send)
+				}(send)
+			}
+		}
+	}
+}
+
+// close Stop polling and wait for the sends in flight.
+func (s *scheduler) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+
+	s.closed = true
+	started := s.started
+	close(s.stop)
+	s.mu.Unlock()
+
+	if started {
+		<-s.done
+	}
+}
+
+var _ ScheduleStore = (*MemoryScheduleStore)(nil)
+
+// MemoryScheduleStore In-memory hashed timer wheel, sends are lost on restart.
+// A send is due at the first tick at or after its time.
+type MemoryScheduleStore struct {
+	mu       sync.Mutex
+	tick     time.Duration
+	slots    []map[string]*wheelEntry
+	entries  map[string]*wheelEntry
+	cursor   int
+	cursorAt time.Time
+}
+
+// wheelEntry A send in the timer wheel, due when its slot is reached with no rounds left.
+type wheelEntry struct {
+	send   *ScheduledSend
+	slot   int
+	rounds int
+}
+
+// NewMemoryScheduleStore New an in-memory timer wheel with the tick, it defaults to DefaultScheduleTick.
+func NewMemoryScheduleStore(tick time.Duration) *MemoryScheduleStore {
+	if tick <= 0 {
+		tick = DefaultScheduleTick
+	}
+
+	slots := make([]map[string]*wheelEntry, DefaultScheduleSlots)
+	for i := range slots {
+		slots[i] = map[string]*wheelEntry{}
+	}
+
+	return &MemoryScheduleStore{
+		tick:     tick,
+		slots:    slots,
+		entries:  map[string]*wheelEntry{},
+		cursorAt: time.Now(),
+	}
+}
+
+func (m *MemoryScheduleStore) Add(send *ScheduledSend) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(send.ID)
+	m.place(&wheelEntry{send: send})
+
+	return nil
+}
+
+func (m *MemoryScheduleStore) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.remove(id) {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (m *MemoryScheduleStore) Due(now time.Time) ([]*ScheduledSend, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	steps := int(now.Sub(m.cursorAt) / m.tick)
+	if steps <= 0 {
+		return nil, nil
+	}
+
+	var due []*ScheduledSend
+
+	if steps > len(m.slots) {
+		// The whole wheel was skipped, place the pending entries again.
+		m.cursor = (m.cursor + steps) % len(m.slots)
+		m.cursorAt = m.cursorAt.Add(time.Duration(steps) * m.tick)
+
+		entries := m.entries
+		m.entries = map[string]*wheelEntry{}
+		for i := range m.slots {
+			m.slots[i] = map[string]*wheelEntry{}
+		}
+
+		for _, entry := range entries {
+			if !entry.send.At.After(m.cursorAt) {
+				due = append(due, entry.send)
+			} else {
+				m.place(entry)
+			}
+		}
+	} else {
+		for i := 0; i < steps; i++ {
+			m.cursor = (m.cursor + 1) % len(m.slots)
+			m.cursorAt = m.cursorAt.Add(m.tick)
+
+			for id, entry := range m.slots[m.cursor] {
+				if entry.rounds > 0 {
+					entry.rounds--
+					continue
+				}
+
+				due = append(due, entry.send)
+				delete(m.slots[m.cursor], id)
+				delete(m.entries, id)
+			}
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].At.Equal(due[j].At) {
+			return due[i].At.Before(due[j].At)
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	return due, nil
+}
+
+// Len Number of pending sends.
+func (m *MemoryScheduleStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.entries)
+}
+
+// place Put the entry in the slot of the first tick at or after its time.
+func (m *MemoryScheduleStore) place(entry *wheelEntry) {
+	ticks := int((entry.send.At.Sub(m.cursorAt) + m.tick - 1) / m.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	entry.slot = (m.cursor + ticks) % len(m.slots)
+	entry.rounds = (ticks - 1) / len(m.slots)
+
+	m.slots[entry.slot][entry.send.ID] = entry
+	m.entries[entry.send.ID] = entry
+}
+
+// remove Remove the entry, false if it is not in the wheel.
+func (m *MemoryScheduleStore) remove(id string) bool {
+	entry, ok := m.entries[id]
+	if !ok {
+		return false
+	}
+
+	delete(m.slots[entry.slot], id)
+	delete(m.entries, id)
+
+	return true
+}
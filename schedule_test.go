@@ -0,0 +1,253 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestScheduledSend(id string, at time.Time) *ScheduledSend {
+	return &ScheduledSend{ID: id, At: at, To: NewPhoneNumberWithoutIDDCode(18888888888)}
+}
+
+func TestMemoryScheduleStore(t *testing.T) {
+	store := NewMemoryScheduleStore(time.Second)
+	start := store.cursorAt
+
+	assert.NoError(t, store.Add(newTestScheduledSend("a", start.Add(1500*time.Millisecond))))
+	assert.NoError(t, store.Add(newTestScheduledSend("b", start.Add(time.Second))))
+	assert.NoError(t, store.Add(newTestScheduledSend("c", start.Add(time.Duration(DefaultScheduleSlots+3)*time.Second))))
+	assert.NoError(t, store.Add(newTestScheduledSend("d", start.Add(time.Minute))))
+	assert.Equal(t, 4, store.Len())
+
+	due, err := store.Due(start.Add(999 * time.Millisecond))
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, _ = store.Due(start.Add(time.Second))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "b", due[0].ID)
+	}
+
+	due, _ = store.Due(start.Add(2 * time.Second))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "a", due[0].ID)
+	}
+
+	assert.NoError(t, store.Remove("d"))
+	assert.ErrorIs(t, store.Remove("d"), ErrScheduleNotFound)
+
+	// One lap of the wheel does not fire c.
+	due, _ = store.Due(start.Add(time.Duration(DefaultScheduleSlots+2) * time.Second))
+	assert.Empty(t, due)
+
+	due, _ = store.Due(start.Add(time.Duration(DefaultScheduleSlots+3) * time.Second))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "c", due[0].ID)
+	}
+
+	assert.Zero(t, store.Len())
+}
+
+func TestMemoryScheduleStore_Skipped(t *testing.T) {
+	store := NewMemoryScheduleStore(time.Second)
+	start := store.cursorAt
+
+	far := time.Duration(3*DefaultScheduleSlots) * time.Second
+
+	assert.NoError(t, store.Add(newTestScheduledSend("a", start.Add(10*time.Second))))
+	assert.NoError(t, store.Add(newTestScheduledSend("b", start.Add(far+time.Second))))
+
+	due, _ := store.Due(start.Add(far))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "a", due[0].ID)
+	}
+
+	due, _ = store.Due(start.Add(far + time.Second))
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "b", due[0].ID)
+	}
+}
+
+func TestGsms_Schedule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{}
+
+	var sentAt atomic.Value
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"count"}),
+		WithScheduleStore(NewMemoryScheduleStore(10*time.Millisecond)),
+		WithScheduleInterval(10*time.Millisecond),
+		WithScheduleCallback(func(send *ScheduledSend, results []*Result, err error) {
+			assert.NoError(t, err)
+			sentAt.Store(time.Now())
+		}),
+	)
+	defer g.Close()
+
+	at := time.Now().Add(50 * time.Millisecond)
+
+	handle, err := g.Schedule(at, 18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&gateway.calls))
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&gateway.calls) == 1 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return sentAt.Load() != nil }, time.Second, time.Millisecond)
+	assert.False(t, sentAt.Load().(time.Time).Before(at))
+
+	assert.ErrorIs(t, handle.Cancel(), ErrScheduleNotFound)
+}
+
+func TestWithScheduleInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		g := New(nil, WithScheduleInterval(interval))
+		assert.Equal(t, DefaultScheduleTick, g.scheduler.interval)
+	}
+
+	g := New(nil, WithScheduleInterval(time.Second))
+	assert.Equal(t, time.Second, g.scheduler.interval)
+}
+
+func TestGsms_Schedule_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"count"}),
+		WithScheduleStore(NewMemoryScheduleStore(10*time.Millisecond)),
+		WithScheduleInterval(10*time.Millisecond),
+	)
+
+	handle, err := g.Schedule(time.Now().Add(30*time.Millisecond), 18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, handle.Cancel())
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Zero(t, atomic.LoadInt32(&gateway.calls))
+
+	assert.NoError(t, g.Close())
+
+	_, err = g.Schedule(time.Now(), 18888888888, newAnyMessage(ctrl))
+	assert.ErrorIs(t, err, ErrSchedulerClosed)
+}
+
+func newScheduleGateway(ctrl *gomock.Controller, name string) *MockScheduleGateway {
+	gateway := NewMockScheduleGateway(ctrl)
+	gateway.EXPECT().Name().Return(name).AnyTimes()
+	return gateway
+}
+
+func TestGsms_Schedule_Native(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	at := time.Now().Add(time.Hour)
+	receipt := &Receipt{MessageID: "1001"}
+
+	a := newScheduleGateway(ctrl, "a")
+	a.EXPECT().SendAt(gomock.Any(), gomock.Any(), gomock.Any(), at, gomock.Any()).Return(nil, errors.New("unavailable"))
+
+	b := newScheduleGateway(ctrl, "b")
+	b.EXPECT().SendAt(gomock.Any(), gomock.Any(), gomock.Any(), at, gomock.Any()).Return(receipt, nil)
+	b.EXPECT().CancelScheduled(gomock.Any(), receipt, gomock.Any()).Return(nil)
+
+	g := New([]Gateway{a, b}, WithGateways([]string{"a", "b"}))
+	defer g.Close()
+
+	handle, err := g.Schedule(at, 18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, handle.Native())
+
+	if assert.Len(t, handle.Results, 2) {
+		assert.Equal(t, StatusFailure, handle.Results[0].Status)
+		assert.Equal(t, "b", handle.Results[1].Gateway)
+		assert.Equal(t, StatusSuccess, handle.Results[1].Status)
+		assert.Same(t, receipt, handle.Results[1].Receipt)
+	}
+
+	assert.NoError(t, handle.Cancel())
+}
+
+func TestGsms_Schedule_Native_Failed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	a := newScheduleGateway(ctrl, "a")
+	a.EXPECT().SendAt(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("unavailable"))
+
+	g := New([]Gateway{a}, WithGateways([]string{"a"}))
+	defer g.Close()
+
+	_, err := g.Schedule(time.Now().Add(time.Hour), 18888888888, newAnyMessage(ctrl))
+
+	var failed *ErrGatewaysFailed
+	if assert.ErrorAs(t, err, &failed) {
+		assert.Len(t, failed.Results, 1)
+	}
+}
+
+func TestGsms_Schedule_Native_Unsupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Not every gateway schedules at the provider, SendAt must not be called.
+	a := newScheduleGateway(ctrl, "a")
+
+	g := New([]Gateway{a, &countGateway{}}, WithGateways([]string{"a", "count"}))
+	defer g.Close()
+
+	handle, err := g.Schedule(time.Now().Add(time.Hour), 18888888888, newAnyMessage(ctrl))
+	if assert.NoError(t, err) {
+		assert.False(t, handle.Native())
+		assert.NoError(t, handle.Cancel())
+	}
+}
+
+func TestGsms_Schedule_IdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gateway := &countGateway{}
+
+	var sent int32
+
+	g := New([]Gateway{gateway},
+		WithGateways([]string{"count"}),
+		WithIdempotencyStore(NewMemoryIdempotencyStore()),
+		WithScheduleStore(NewMemoryScheduleStore(10*time.Millisecond)),
+		WithScheduleInterval(10*time.Millisecond),
+		WithScheduleCallback(func(send *ScheduledSend, results []*Result, err error) {
+			assert.Equal(t, "order-1", send.IdempotencyKey)
+			atomic.AddInt32(&sent, 1)
+		}),
+	)
+	defer g.Close()
+
+	ctx := ContextWithIdempotencyKey(context.Background(), "order-1")
+
+	// The same send scheduled twice is sent once.
+	for i := 0; i < 2; i++ {
+		_, err := g.ScheduleContext(ctx, time.Now().Add(20*time.Millisecond), 18888888888, newAnyMessage(ctrl))
+		assert.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&sent) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&gateway.calls))
+}
@@ -0,0 +1,113 @@
+package gsms
+
+import (
+	"context"
+	"time"
+)
+
+// SendMode How gateways are tried when sending a message.
+type SendMode int
+
+const (
+	// SendModeSequential try gateways one by one, the next one starts after the previous one failed.
+	SendModeSequential SendMode = iota
+	// SendModeRace send via all gateways concurrently, the first success wins.
+	SendModeRace
+	// SendModeHedged start the next gateway when the previous one failed or has not finished within the hedge delay.
+	SendModeHedged
+)
+
+// sendConcurrently Send the message via gateways concurrently according to the send mode.
+// The first success cancels the other attempts, every launched attempt is reported in the results.
+// No more gateways are launched after a recipient error.
+func (g *Gsms) sendConcurrently(ctx context.Context, s *sending, gateways []string) ([]*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(gateways) == 0 {
+		return nil, NewErrGatewayFailed(nil)
+	}
+
+	start := time.Now()
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make([][]*Result, len(gateways))
+	done := make(chan int, len(gateways))
+	launched, running := 0, 0
+
+	launch := func() {
+		i := launched
+		launched++
+		running++
+
+		go func() {
+			attempts[i] = g.attemptWithRetry(attemptCtx, s, gateways[i])
+			done <- i
+		}()
+	}
+
+	var hedge <-chan time.Time
+
+	if g.sendMode == SendModeRace {
+		for launched < len(gateways) {
+			launch()
+		}
+	} else {
+		launch()
+	}
+
+	isSuccessful, isRecipientFailure := false, false
+
+	for running > 0 {
+		if g.sendMode == SendModeHedged && !isSuccessful && !isRecipientFailure && launched < len(gateways) && hedge == nil {
+			hedge = time.After(g.hedgeDelay)
+		}
+
+		select {
+		case i := <-done:
+			running--
+			result := attempts[i][len(attempts[i])-1]
+
+			if result.Status == StatusSuccess && !isSuccessful {
+				isSuccessful = true
+				cancel()
+				continue
+			}
+
+			if isRecipientError(result.Error) {
+				isRecipientFailure = true
+			}
+
+			if !isSuccessful && !isRecipientFailure && ctx.Err() == nil && launched < len(gateways) {
+				hedge = nil
+				g.events.emitFailover(s.to, attempts[i], gateways[launched])
+				launch()
+			}
+		case <-hedge:
+			hedge = nil
+			if !isSuccessful && !isRecipientFailure && launched < len(gateways) {
+				launch()
+			}
+		}
+	}
+
+	if !isSuccessful && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var results []*Result
+	for _, gatewayAttempts := range attempts[:launched] {
+		results = append(results, gatewayAttempts...)
+	}
+
+	if !isSuccessful {
+		err := NewErrGatewayFailed(results)
+		g.events.emitSendExhausted(s.to, results, time.Since(start), err)
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,150 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type delayGateway struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (d *delayGateway) Name() string {
+	return d.name
+}
+
+func (d *delayGateway) Send(to *PhoneNumber, message Message, config *Config) error {
+	return d.SendContext(context.Background(), to, message, config)
+}
+
+func (d *delayGateway) SendContext(ctx context.Context, to *PhoneNumber, message Message, config *Config) error {
+	select {
+	case <-time.After(d.delay):
+		return d.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newAnyMessage(ctrl *gomock.Controller) *MockMessage {
+	mockMessage := NewMockMessage(ctrl)
+	mockMessage.EXPECT().Gateways().Return(nil, nil).AnyTimes()
+	mockMessage.EXPECT().Strategy().Return(nil, nil).AnyTimes()
+	mockMessage.EXPECT().GetTemplate(gomock.Any()).Return("SMS_00000001", nil).AnyTimes()
+	return mockMessage
+}
+
+func TestGsms_Send_Race(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", delay: time.Second},
+		&delayGateway{name: "b", delay: 10 * time.Millisecond},
+	}, WithGateways([]string{"a", "b"}), WithSendMode(SendModeRace))
+
+	start := time.Now()
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Gateway)
+	assert.Equal(t, StatusFailure, results[0].Status)
+	assert.ErrorIs(t, results[0].Error, context.Canceled)
+	assert.Equal(t, "b", results[1].Gateway)
+	assert.Equal(t, StatusSuccess, results[1].Status)
+}
+
+func TestGsms_Send_Hedged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", delay: time.Second},
+		&delayGateway{name: "b", delay: 10 * time.Millisecond},
+		&delayGateway{name: "c", delay: 10 * time.Millisecond},
+	}, WithGateways([]string{"a", "b", "c"}), WithSendMode(SendModeHedged), WithHedgeDelay(20*time.Millisecond))
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, StatusFailure, results[0].Status)
+	assert.Equal(t, StatusSuccess, results[1].Status)
+}
+
+func TestGsms_Send_Hedged_FailoverImmediately(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", err: errors.New("send failed")},
+		&delayGateway{name: "b"},
+	}, WithGateways([]string{"a", "b"}), WithSendMode(SendModeHedged), WithHedgeDelay(time.Hour))
+
+	results, err := g.Send(18888888888, newAnyMessage(ctrl))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, StatusSuccess, results[1].Status)
+}
+
+func TestGsms_Send_Race_AllFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", err: errors.New("send failed")},
+		&delayGateway{name: "b", err: errors.New("send failed")},
+	}, WithGateways([]string{"a", "b"}), WithSendMode(SendModeRace))
+
+	_, err := g.Send(18888888888, newAnyMessage(ctrl))
+
+	var failed *ErrGatewaysFailed
+	if assert.ErrorAs(t, err, &failed) {
+		assert.Len(t, failed.Results, 2)
+	}
+}
+
+func TestGsms_Send_Race_ObserveRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	to := NewPhoneNumber(18888888888, "86")
+
+	// the cancelled loser is not fed back, so a sticky route is kept
+	strategy := NewMockRoutingFeedbackStrategy(ctrl)
+	strategy.EXPECT().Route(gomock.Any(), gomock.Any(), gomock.Any()).Return([]string{"a", "b"})
+
+	var observed []string
+	strategy.EXPECT().ObserveRoute(to, gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Do(
+		func(to *PhoneNumber, gateway string, latency time.Duration, err error) {
+			observed = append(observed, gateway)
+		},
+	)
+
+	g := New([]Gateway{
+		&delayGateway{name: "a", delay: time.Second},
+		&delayGateway{name: "b", delay: 10 * time.Millisecond},
+	}, WithGateways([]string{"a", "b"}), WithSendMode(SendModeRace), WithStrategy(strategy))
+
+	results, err := g.Send(to, newAnyMessage(ctrl))
+	if assert.NoError(t, err) && assert.Len(t, results, 2) {
+		assert.ErrorIs(t, results[0].Error, context.Canceled)
+	}
+
+	assert.Equal(t, []string{"b"}, observed)
+}
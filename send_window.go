@@ -0,0 +1,220 @@
+package gsms
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// CategoryOTP Verification codes, they bypass the send windows.
+	CategoryOTP = "otp"
+	// CategoryNotification Notifications, e.g. order and delivery updates.
+	CategoryNotification = "notification"
+	// CategoryMarketing Marketing messages.
+	CategoryMarketing = "marketing"
+)
+
+// iddLocations Time zones of the IDD codes, countries spanning several time zones use the zone of their capital.
+var iddLocations = map[int]string{
+	1:   "America/New_York",
+	7:   "Europe/Moscow",
+	33:  "Europe/Paris",
+	34:  "Europe/Madrid",
+	39:  "Europe/Rome",
+	44:  "Europe/London",
+	49:  "Europe/Berlin",
+	55:  "America/Sao_Paulo",
+	60:  "Asia/Kuala_Lumpur",
+	61:  "Australia/Sydney",
+	62:  "Asia/Jakarta",
+	63:  "Asia/Manila",
+	65:  "Asia/Singapore",
+	66:  "Asia/Bangkok",
+	81:  "Asia/Tokyo",
+	82:  "Asia/Seoul",
+	84:  "Asia/Ho_Chi_Minh",
+	86:  "Asia/Shanghai",
+	91:  "Asia/Kolkata",
+	852: "Asia/Hong_Kong",
+	853: "Asia/Macau",
+	886: "Asia/Taipei",
+	971: "Asia/Dubai",
+}
+
+// loadedLocations Cache of the loaded iddLocations, nil if the time zone database lacks the location.
+var loadedLocations sync.Map
+
+// locationContextKey Context key of the time zone of the recipient.
+type locationContextKey struct{}
+
+// ContextWithLocation Set the time zone of the recipient of the sends with the context,
+// it takes precedence over the time zone derived from the IDD code.
+func ContextWithLocation(ctx context.Context, location *time.Location) context.Context {
+	return context.WithValue(ctx, locationContextKey{}, location)
+}
+
+// LocationFromContext Get the time zone set on the context.
+func LocationFromContext(ctx context.Context) (*time.Location, bool) {
+	location, ok := ctx.Value(locationContextKey{}).(*time.Location)
+	return location, ok && location != nil
+}
+
+// SendWindow Allowed local time of day, from Start to End since midnight.
+// A window with End before Start spans midnight, e.g. 20:00 to 02:00.
+type SendWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// contains Whether the time of day is in the window.
+func (w *SendWindow) contains(timeOfDay time.Duration) bool {
+	if w.Start < w.End {
+		return timeOfDay >= w.Start && timeOfDay < w.End
+	}
+
+	return timeOfDay >= w.Start || timeOfDay < w.End
+}
+
+// next Start of the next window after local, false if local is outside the window.
+func (w *SendWindow) next(local time.Time) (time.Time, bool) {
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	if w.contains(local.Sub(midnight)) {
+		return local, true
+	}
+
+	start := midnight.Add(w.Start)
+	if !start.After(local) {
+		start = midnight.AddDate(0, 0, 1).Add(w.Start)
+	}
+
+	return start, false
+}
+
+// SendWindowPolicy Send windows per message category in the local time of the recipient.
+// CategoryOTP messages are never restricted.
+type SendWindowPolicy struct {
+	// Windows Send windows keyed by category.
+	Windows map[string]*SendWindow
+	// Default Send window of the categories without one, messages are not restricted if it is nil.
+	Default *SendWindow
+	// Locations Time zones keyed by IDD code, overriding the built-in ones. Numbers without IDD code use 86.
+	Locations map[int]*time.Location
+	// DefaultLocation Time zone of the IDD codes without one, it defaults to time.Local.
+	DefaultLocation *time.Location
+	// Defer Schedule the messages outside their window at the start of the next window instead of rejecting them.
+	Defer bool
+}
+
+// window Send window of the category, nil if the category is not restricted.
+func (p *SendWindowPolicy) window(category string) *SendWindow {
+	if category == CategoryOTP {
+		return nil
+	}
+
+	if window, ok := p.Windows[category]; ok {
+		return window
+	}
+
+	return p.Default
+}
+
+// location Time zone of the recipient.
+func (p *SendWindowPolicy) location(ctx context.Context, to *PhoneNumber) *time.Location {
+	if location, ok := LocationFromContext(ctx); ok {
+		return location
+	}
+
+	iddCode := to.IDDCode()
+	if iddCode == 0 {
+		iddCode = 86
+	}
+
+	if location, ok := p.Locations[iddCode]; ok && location != nil {
+		return location
+	}
+
+	if location := loadIDDLocation(iddCode); location != nil {
+		return location
+	}
+
+	if p.DefaultLocation != nil {
+		return p.DefaultLocation
+	}
+
+	return time.Local
+}
+
+// loadIDDLocation Load the built-in time zone of the IDD code, nil if there is none.
+func loadIDDLocation(iddCode int) *time.Location {
+	if location, ok := loadedLocations.Load(iddCode); ok {
+		return location.(*time.Location)
+	}
+
+	name, ok := iddLocations[iddCode]
+	if !ok {
+		return nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		location = nil
+	}
+
+	loadedLocations.Store(iddCode, location)
+
+	return location
+}
+
+// categoryOf Get the category of the message.
+func categoryOf(message Message) string {
+	if message, ok := message.(CategorizedMessage); ok {
+		return message.GetCategory()
+	}
+
+	return ""
+}
+
+// nextSendWindow Whether the message can be sent to the recipient at the time, otherwise the start of the next window.
+func (g *Gsms) nextSendWindow(ctx context.Context, to *PhoneNumber, message Message, at time.Time) (time.Time, bool) {
+	if g.sendWindowPolicy == nil {
+		return at, true
+	}
+
+	window := g.sendWindowPolicy.window(categoryOf(message))
+	if window == nil {
+		return at, true
+	}
+
+	return window.next(at.In(g.sendWindowPolicy.location(ctx, to)))
+}
+
+// checkSendWindow Check the send window of the recipient at the time the message is sent.
+// Outside the window it returns ErrOutsideSendWindow, or ErrSendDeferred once the message is scheduled at the
+// start of the next window.
+func (g *Gsms) checkSendWindow(ctx context.Context, to *PhoneNumber, message Message, gateways []string) error {
+	if g.sendWindowPolicy == nil {
+		return nil
+	}
+
+	category := categoryOf(message)
+
+	next, ok := g.nextSendWindow(ctx, to, message, time.Now())
+	if ok {
+		return nil
+	}
+
+	if !g.sendWindowPolicy.Defer {
+		return NewErrOutsideSendWindow(to, category, next)
+	}
+
+	handle, err := g.ScheduleContext(ctx, next, to, message, gateways...)
+	if err != nil {
+		return err
+	}
+
+	g.config.Logger.Infof("[%s] outside send window of category %s, deferred to %s", to, category, next)
+
+	return NewErrSendDeferred(to, category, handle)
+}
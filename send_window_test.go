@@ -0,0 +1,180 @@
+package gsms
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCategorizedMessage(ctrl *gomock.Controller, category string) *MockCategorizedMessage {
+	mockMessage := NewMockCategorizedMessage(ctrl)
+	mockMessage.EXPECT().Gateways().Return(nil, nil).AnyTimes()
+	mockMessage.EXPECT().Strategy().Return(nil, nil).AnyTimes()
+	mockMessage.EXPECT().GetTemplate(gomock.Any()).Return("SMS_00000001", nil).AnyTimes()
+	mockMessage.EXPECT().GetCategory().Return(category).AnyTimes()
+	return mockMessage
+}
+
+// closedWindow A window of an hour starting two hours after now in UTC.
+func closedWindow() (*SendWindow, time.Time) {
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	start := now.Add(2 * time.Hour).Truncate(time.Minute)
+	window := &SendWindow{
+		Start: start.Sub(midnight) % (24 * time.Hour),
+		End:   start.Add(time.Hour).Sub(midnight) % (24 * time.Hour),
+	}
+
+	return window, start
+}
+
+func TestSendWindow_next(t *testing.T) {
+	location := time.FixedZone("CST", 8*3600)
+	day := func(hour int) time.Time {
+		return time.Date(2023, 6, 1, hour, 0, 0, 0, location)
+	}
+
+	window := &SendWindow{Start: 8 * time.Hour, End: 21 * time.Hour}
+
+	next, ok := window.next(day(7))
+	assert.False(t, ok)
+	assert.Equal(t, day(8), next)
+
+	_, ok = window.next(day(8))
+	assert.True(t, ok)
+
+	next, ok = window.next(day(21))
+	assert.False(t, ok)
+	assert.Equal(t, day(32), next)
+
+	overnight := &SendWindow{Start: 20 * time.Hour, End: 2 * time.Hour}
+
+	_, ok = overnight.next(day(1))
+	assert.True(t, ok)
+
+	_, ok = overnight.next(day(23))
+	assert.True(t, ok)
+
+	next, ok = overnight.next(day(3))
+	assert.False(t, ok)
+	assert.Equal(t, day(20), next)
+}
+
+func TestSendWindowPolicy_location(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	fallback := time.FixedZone("UTC-3", -3*3600)
+
+	policy := &SendWindowPolicy{
+		Locations:       map[int]*time.Location{81: tokyo},
+		DefaultLocation: fallback,
+	}
+
+	ctx := context.Background()
+
+	assert.Equal(t, tokyo, policy.location(ctx, NewPhoneNumber(9012345678, "81")))
+	assert.Equal(t, fallback, policy.location(ctx, NewPhoneNumber(12345678, "999")))
+	assert.Equal(t, time.UTC, policy.location(ContextWithLocation(ctx, time.UTC), NewPhoneNumber(9012345678, "81")))
+
+	if location := loadIDDLocation(86); location != nil {
+		assert.Equal(t, "Asia/Shanghai", policy.location(ctx, NewPhoneNumberWithoutIDDCode(18888888888)).String())
+	}
+}
+
+func TestGsms_Send_SendWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	window, start := closedWindow()
+
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithSendWindowPolicy(&SendWindowPolicy{
+		Windows:   map[string]*SendWindow{CategoryMarketing: window},
+		Locations: map[int]*time.Location{86: time.UTC},
+	}))
+
+	_, err := g.Send(18888888888, newCategorizedMessage(ctrl, CategoryMarketing))
+
+	var outside *ErrOutsideSendWindow
+	if assert.True(t, errors.As(err, &outside)) {
+		assert.Equal(t, CategoryMarketing, outside.Category)
+		assert.True(t, start.Equal(outside.NextWindow))
+	}
+
+	// Categories without a window and OTP are not restricted.
+	_, err = g.Send(18888888888, newCategorizedMessage(ctrl, CategoryNotification))
+	assert.NoError(t, err)
+
+	g.sendWindowPolicy.Default = window
+
+	_, err = g.Send(18888888888, newCategorizedMessage(ctrl, CategoryOTP))
+	assert.NoError(t, err)
+
+	_, err = g.Send(18888888888, newAnyMessage(ctrl))
+	assert.True(t, errors.As(err, &outside))
+
+	assert.Equal(t, int32(2), gateway.calls)
+}
+
+func TestGsms_Send_SendWindow_Defer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	window, start := closedWindow()
+
+	store := NewMemoryScheduleStore(time.Second)
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithScheduleStore(store), WithSendWindowPolicy(&SendWindowPolicy{
+		Default: window,
+		Defer:   true,
+	}))
+	defer g.Close()
+
+	ctx := ContextWithLocation(context.Background(), time.UTC)
+
+	_, err := g.SendContext(ctx, 18888888888, newCategorizedMessage(ctrl, CategoryMarketing))
+
+	var deferred *ErrSendDeferred
+	if assert.True(t, errors.As(err, &deferred)) {
+		assert.True(t, start.Equal(deferred.Handle.At))
+		assert.Equal(t, 1, store.Len())
+		assert.NoError(t, deferred.Handle.Cancel())
+	}
+
+	assert.Zero(t, atomic.LoadInt32(&gateway.calls))
+}
+
+func TestGsms_SendBatch_SendWindow_Defer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	window, _ := closedWindow()
+
+	store := NewMemoryScheduleStore(time.Second)
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithScheduleStore(store), WithSendWindowPolicy(&SendWindowPolicy{
+		Windows:   map[string]*SendWindow{CategoryMarketing: window},
+		Locations: map[int]*time.Location{86: time.UTC, 81: time.FixedZone("UTC+2:30", 9000)},
+		Defer:     true,
+	}))
+	defer g.Close()
+
+	results, err := g.SendBatch([]*PhoneNumber{
+		NewPhoneNumber(18888888888, "86"),
+		NewPhoneNumber(18888888889, "81"),
+	}, newCategorizedMessage(ctrl, CategoryMarketing))
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The window opens 2 hours later in UTC and is open in UTC+2:30.
+	assert.Equal(t, StatusDeferred, results[0].Status)
+	assert.Equal(t, StatusSuccess, results[1].Status)
+	assert.Equal(t, 1, store.Len())
+	assert.Equal(t, int32(1), gateway.calls)
+}
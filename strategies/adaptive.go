@@ -0,0 +1,142 @@
+package strategies
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultAdaptiveAlpha Default smoothing factor of the moving averages.
+const DefaultAdaptiveAlpha = 0.2
+
+// DefaultReferenceLatency Default latency at which a gateway's score is halved.
+const DefaultReferenceLatency = time.Second
+
+// AdaptiveStrategy Order gateways by their observed success rate and latency.
+// Outcomes are fed back by gsms after every attempt, both are tracked as exponentially weighted moving averages.
+// Gateways that have not been observed yet are scored optimistically so they get tried.
+type AdaptiveStrategy struct {
+	// Alpha Smoothing factor in (0, 1], higher values react faster to recent outcomes.
+	Alpha float64
+	// Exploration Probability of moving a random non-leading gateway to the front.
+	Exploration float64
+	// ReferenceLatency Latency at which a gateway's score is halved.
+	ReferenceLatency time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*gatewayStats
+	rand  *rand.Rand
+}
+
+// gatewayStats Moving averages of a gateway.
+type gatewayStats struct {
+	successRate float64
+	latency     float64
+}
+
+// NewAdaptiveStrategy New an adaptive strategy.
+func NewAdaptiveStrategy(alpha, exploration float64) *AdaptiveStrategy {
+	return NewAdaptiveStrategyWithSeed(alpha, exploration, time.Now().UnixNano())
+}
+
+// NewAdaptiveStrategyWithSeed New an adaptive strategy with a deterministic seed.
+func NewAdaptiveStrategyWithSeed(alpha, exploration float64, seed int64) *AdaptiveStrategy {
+	return &AdaptiveStrategy{
+		Alpha:       alpha,
+		Exploration: exploration,
+		rand:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (a *AdaptiveStrategy) Apply(gateways []string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]string, len(gateways))
+	copy(result, gateways)
+
+	scores := make(map[string]float64, len(result))
+	for _, gateway := range result {
+		scores[gateway] = a.score(gateway)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return scores[result[i]] > scores[result[j]]
+	})
+
+	if len(result) > 1 && a.Exploration > 0 {
+		if a.rand == nil {
+			a.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+
+		if a.rand.Float64() < a.Exploration {
+			i := 1 + a.rand.Intn(len(result)-1)
+			explored := result[i]
+			copy(result[1:i+1], result[:i])
+			result[0] = explored
+		}
+	}
+
+	return result
+}
+
+// Observe Record the outcome of an attempt, err is nil on success.
+func (a *AdaptiveStrategy) Observe(gateway string, latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stats == nil {
+		a.stats = map[string]*gatewayStats{}
+	}
+
+	success := 0.0
+	if err == nil {
+		success = 1
+	}
+
+	stats, ok := a.stats[gateway]
+	if !ok {
+		a.stats[gateway] = &gatewayStats{
+			successRate: success,
+			latency:     float64(latency),
+		}
+		return
+	}
+
+	alpha := a.alpha()
+	stats.successRate += alpha * (success - stats.successRate)
+	stats.latency += alpha * (float64(latency) - stats.latency)
+}
+
+// Score Score of the gateway, gateways with a higher score are tried first.
+func (a *AdaptiveStrategy) Score(gateway string) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.score(gateway)
+}
+
+// score successRate / (1 + latency / ReferenceLatency).
+func (a *AdaptiveStrategy) score(gateway string) float64 {
+	stats, ok := a.stats[gateway]
+	if !ok {
+		return 1
+	}
+
+	reference := a.ReferenceLatency
+	if reference <= 0 {
+		reference = DefaultReferenceLatency
+	}
+
+	return stats.successRate / (1 + stats.latency/float64(reference))
+}
+
+// alpha Smoothing factor, falls back to DefaultAdaptiveAlpha if out of range.
+func (a *AdaptiveStrategy) alpha() float64 {
+	if a.Alpha <= 0 || a.Alpha > 1 {
+		return DefaultAdaptiveAlpha
+	}
+
+	return a.Alpha
+}
@@ -0,0 +1,68 @@
+package strategies
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveStrategy_Apply(t *testing.T) {
+	s := NewAdaptiveStrategyWithSeed(0.5, 0, 1)
+
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	assert.Equal(t, gateways, s.Apply(gateways))
+
+	for i := 0; i < 5; i++ {
+		s.Observe("aliyun", 100*time.Millisecond, errors.New("send failed"))
+		s.Observe("qcloud", 2*time.Second, nil)
+		s.Observe("yunpian", 100*time.Millisecond, nil)
+	}
+
+	assert.Equal(t, []string{"yunpian", "qcloud", "aliyun"}, s.Apply(gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, gateways)
+}
+
+func TestAdaptiveStrategy_Apply_Unobserved(t *testing.T) {
+	s := NewAdaptiveStrategyWithSeed(0.5, 0, 1)
+
+	s.Observe("aliyun", 100*time.Millisecond, nil)
+
+	assert.Equal(t, []string{"qcloud", "aliyun"}, s.Apply([]string{"aliyun", "qcloud"}))
+}
+
+func TestAdaptiveStrategy_Observe(t *testing.T) {
+	s := &AdaptiveStrategy{Alpha: 0.5}
+
+	s.Observe("aliyun", 0, nil)
+	assert.InDelta(t, 1, s.Score("aliyun"), 1e-9)
+
+	s.Observe("aliyun", 0, errors.New("send failed"))
+	assert.InDelta(t, 0.5, s.Score("aliyun"), 1e-9)
+
+	s.Observe("aliyun", 2*time.Second, nil)
+	assert.InDelta(t, 0.75/2, s.Score("aliyun"), 1e-9)
+}
+
+func TestAdaptiveStrategy_Apply_Exploration(t *testing.T) {
+	s := NewAdaptiveStrategyWithSeed(0.2, 0.1, 1)
+
+	s.Observe("aliyun", 10*time.Millisecond, nil)
+	s.Observe("qcloud", time.Second, nil)
+	s.Observe("yunpian", time.Second, errors.New("send failed"))
+
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+	explored := 0
+
+	for i := 0; i < 10000; i++ {
+		newGateways := s.Apply(gateways)
+
+		assert.ElementsMatch(t, gateways, newGateways)
+		if newGateways[0] != "aliyun" {
+			explored++
+		}
+	}
+
+	assert.InDelta(t, 1000, explored, 150)
+}
@@ -0,0 +1,38 @@
+package strategies
+
+// HealthChecker Report whether a gateway is healthy.
+type HealthChecker interface {
+	Healthy(gateway string) bool
+}
+
+// HealthStrategy Demote unhealthy gateways to the end, the order of the inner strategy is kept otherwise.
+type HealthStrategy struct {
+	// Strategy Inner strategy, the gateways order is kept if nil.
+	Strategy interface {
+		Apply(gateways []string) []string
+	}
+	Health HealthChecker
+}
+
+func (h *HealthStrategy) Apply(gateways []string) []string {
+	if h.Strategy != nil {
+		gateways = h.Strategy.Apply(gateways)
+	}
+
+	if h.Health == nil {
+		return gateways
+	}
+
+	healthy := make([]string, 0, len(gateways))
+	var unhealthy []string
+
+	for _, gateway := range gateways {
+		if h.Health.Healthy(gateway) {
+			healthy = append(healthy, gateway)
+		} else {
+			unhealthy = append(unhealthy, gateway)
+		}
+	}
+
+	return append(healthy, unhealthy...)
+}
@@ -0,0 +1,45 @@
+package strategies
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type healthChecker map[string]bool
+
+func (h healthChecker) Healthy(gateway string) bool {
+	return !h[gateway]
+}
+
+func TestHealthStrategy_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy *HealthStrategy
+		gateways []string
+		want     []string
+	}{
+		{
+			name:     "demote unhealthy gateways",
+			strategy: &HealthStrategy{Health: healthChecker{"aliyun": true}},
+			gateways: []string{"aliyun", "qcloud", "yunpian"},
+			want:     []string{"qcloud", "yunpian", "aliyun"},
+		},
+		{
+			name:     "with inner strategy",
+			strategy: &HealthStrategy{Strategy: &OrderStrategy{}, Health: healthChecker{"qcloud": true}},
+			gateways: []string{"yunpian", "qcloud", "aliyun"},
+			want:     []string{"aliyun", "yunpian", "qcloud"},
+		},
+		{
+			name:     "without health checker",
+			strategy: &HealthStrategy{},
+			gateways: []string{"yunpian", "qcloud", "aliyun"},
+			want:     []string{"yunpian", "qcloud", "aliyun"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.strategy.Apply(tt.gateways))
+		})
+	}
+}
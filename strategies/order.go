@@ -8,6 +8,9 @@ type OrderStrategy struct {
 }
 
 func (o *OrderStrategy) Apply(gateways []string) []string {
-	sort.Strings(gateways)
-	return gateways
+	newGateways := make([]string, len(gateways))
+	copy(newGateways, gateways)
+
+	sort.Strings(newGateways)
+	return newGateways
 }
@@ -0,0 +1,29 @@
+package strategies
+
+import (
+	"sort"
+)
+
+// PriorityStrategy Keep the configured order of gateways.
+// Gateways with explicit priorities come first, lower values first, the others follow in the configured order.
+type PriorityStrategy struct {
+	Priorities map[string]int
+}
+
+func (p *PriorityStrategy) Apply(gateways []string) []string {
+	newGateways := make([]string, len(gateways))
+	copy(newGateways, gateways)
+
+	sort.SliceStable(newGateways, func(i, j int) bool {
+		pi, iok := p.Priorities[newGateways[i]]
+		pj, jok := p.Priorities[newGateways[j]]
+
+		if iok && jok {
+			return pi < pj
+		}
+
+		return iok && !jok
+	})
+
+	return newGateways
+}
@@ -0,0 +1,37 @@
+package strategies
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPriorityStrategy_Apply(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities map[string]int
+		gateways   []string
+		want       []string
+	}{
+		{
+			name:     "configured order",
+			gateways: []string{"yunpian", "aliyun", "qcloud"},
+			want:     []string{"yunpian", "aliyun", "qcloud"},
+		},
+		{
+			name:       "explicit priorities",
+			priorities: map[string]int{"qcloud": 1, "aliyun": 2},
+			gateways:   []string{"yunpian", "aliyun", "submail", "qcloud"},
+			want:       []string{"qcloud", "aliyun", "yunpian", "submail"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateways := append([]string(nil), tt.gateways...)
+
+			s := &PriorityStrategy{Priorities: tt.priorities}
+
+			assert.Equal(t, tt.want, s.Apply(gateways))
+			assert.Equal(t, tt.gateways, gateways)
+		})
+	}
+}
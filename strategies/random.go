@@ -9,13 +9,16 @@ type RandomStrategy struct {
 }
 
 func (o *RandomStrategy) Apply(gateways []string) []string {
+	newGateways := make([]string, len(gateways))
+	copy(newGateways, gateways)
+
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	r.Shuffle(
-		len(gateways),
+		len(newGateways),
 		func(i, j int) {
-			gateways[i], gateways[j] = gateways[j], gateways[i]
+			newGateways[i], newGateways[j] = newGateways[j], newGateways[i]
 		},
 	)
 
-	return gateways
+	return newGateways
 }
@@ -0,0 +1,23 @@
+package strategies
+
+import (
+	"sync/atomic"
+)
+
+// RoundRobinStrategy Rotate the starting gateway on every Apply, it is safe for concurrent use.
+type RoundRobinStrategy struct {
+	next uint64
+}
+
+func (r *RoundRobinStrategy) Apply(gateways []string) []string {
+	newGateways := make([]string, 0, len(gateways))
+
+	if len(gateways) == 0 {
+		return newGateways
+	}
+
+	start := int((atomic.AddUint64(&r.next, 1) - 1) % uint64(len(gateways)))
+
+	newGateways = append(newGateways, gateways[start:]...)
+	return append(newGateways, gateways[:start]...)
+}
@@ -0,0 +1,45 @@
+package strategies
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestRoundRobinStrategy_Apply(t *testing.T) {
+	s := &RoundRobinStrategy{}
+
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, s.Apply(gateways))
+	assert.Equal(t, []string{"qcloud", "yunpian", "aliyun"}, s.Apply(gateways))
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, s.Apply(gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, s.Apply(gateways))
+	assert.Equal(t, []string{"aliyun", "qcloud", "yunpian"}, gateways)
+	assert.Empty(t, s.Apply(nil))
+}
+
+func TestRoundRobinStrategy_Apply_Concurrent(t *testing.T) {
+	s := &RoundRobinStrategy{}
+
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	var mu sync.Mutex
+	first := map[string]int{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 300; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			newGateways := s.Apply(gateways)
+
+			mu.Lock()
+			first[newGateways[0]]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"aliyun": 100, "qcloud": 100, "yunpian": 100}, first)
+}
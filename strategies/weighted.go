@@ -0,0 +1,71 @@
+package strategies
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// WeightedStrategy Order gateways by weighted random picks without replacement.
+// The first gateway is picked proportional to its weight, the remaining ones follow as fallbacks in weighted order.
+// Gateways without a positive weight are appended in their original order.
+type WeightedStrategy struct {
+	Weights map[string]int
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewWeightedStrategy New a weighted strategy.
+func NewWeightedStrategy(weights map[string]int) *WeightedStrategy {
+	return NewWeightedStrategyWithSeed(weights, time.Now().UnixNano())
+}
+
+// NewWeightedStrategyWithSeed New a weighted strategy with a deterministic seed.
+func NewWeightedStrategyWithSeed(weights map[string]int, seed int64) *WeightedStrategy {
+	return &WeightedStrategy{
+		Weights: weights,
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (w *WeightedStrategy) Apply(gateways []string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.rand == nil {
+		w.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	candidates := make([]string, 0, len(gateways))
+	var rest []string
+	total := 0
+
+	for _, gateway := range gateways {
+		if weight := w.Weights[gateway]; weight > 0 {
+			candidates = append(candidates, gateway)
+			total += weight
+		} else {
+			rest = append(rest, gateway)
+		}
+	}
+
+	result := make([]string, 0, len(gateways))
+
+	for len(candidates) > 0 {
+		n := w.rand.Intn(total)
+
+		for i, gateway := range candidates {
+			weight := w.Weights[gateway]
+			if n < weight {
+				result = append(result, gateway)
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				total -= weight
+				break
+			}
+			n -= weight
+		}
+	}
+
+	return append(result, rest...)
+}
@@ -0,0 +1,50 @@
+package strategies
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeightedStrategy_Apply(t *testing.T) {
+	s := NewWeightedStrategyWithSeed(map[string]int{
+		"aliyun": 70,
+		"qcloud": 30,
+	}, 1)
+
+	gateways := []string{"yunpian", "aliyun", "qcloud"}
+	first := map[string]int{}
+
+	for i := 0; i < 10000; i++ {
+		newGateways := s.Apply(gateways)
+
+		assert.Len(t, newGateways, 3)
+		assert.Equal(t, "yunpian", newGateways[2])
+		first[newGateways[0]]++
+	}
+
+	assert.Equal(t, []string{"yunpian", "aliyun", "qcloud"}, gateways)
+	assert.InDelta(t, 7000, first["aliyun"], 300)
+	assert.InDelta(t, 3000, first["qcloud"], 300)
+}
+
+func TestWeightedStrategy_Apply_Deterministic(t *testing.T) {
+	weights := map[string]int{
+		"aliyun":  5,
+		"qcloud":  3,
+		"yunpian": 2,
+	}
+	gateways := []string{"aliyun", "qcloud", "yunpian"}
+
+	s1 := NewWeightedStrategyWithSeed(weights, 42)
+	s2 := NewWeightedStrategyWithSeed(weights, 42)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, s1.Apply(gateways), s2.Apply(gateways))
+	}
+}
+
+func TestWeightedStrategy_Apply_WithoutWeights(t *testing.T) {
+	s := &WeightedStrategy{}
+
+	assert.Equal(t, []string{"yunpian", "aliyun"}, s.Apply([]string{"yunpian", "aliyun"}))
+}
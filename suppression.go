@@ -0,0 +1,278 @@
+package gsms
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// suppressionHeader Columns of the suppression CSV.
+var suppressionHeader = []string{"number", "idd_code", "category", "reason", "created_at"}
+
+// Suppression A recipient that must not receive messages of the category.
+type Suppression struct {
+	To *PhoneNumber
+	// Category Suppressed category, empty to suppress every category.
+	Category  string
+	Reason    string
+	CreatedAt time.Time
+}
+
+// checkSuppression Check the suppression list, store errors fail the send since suppressions are mandatory.
+func (g *Gsms) checkSuppression(to *PhoneNumber, message Message) error {
+	if g.suppressionStore == nil {
+		return nil
+	}
+
+	category := categoryOf(message)
+
+	suppression, ok, err := g.suppressionStore.Check(to, category)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		g.config.Logger.Infof("[%s] recipient suppressed for category %s, skip sending", to, category)
+		return NewErrRecipientSuppressed(to, category, suppression)
+	}
+
+	return nil
+}
+
+// suppressionKey Key of the recipient, numbers without IDD code are treated as chinese mainland numbers.
+func suppressionKey(to *PhoneNumber) string {
+	iddCode := to.IDDCode()
+	if iddCode == 0 {
+		iddCode = 86
+	}
+
+	return fmt.Sprintf("+%d%d", iddCode, to.Number())
+}
+
+var _ SuppressionStore = (*MemorySuppressionStore)(nil)
+
+// MemorySuppressionStore In-memory suppression list.
+type MemorySuppressionStore struct {
+	mu           sync.RWMutex
+	suppressions map[string]map[string]*Suppression
+}
+
+// NewMemorySuppressionStore New an in-memory suppression list.
+func NewMemorySuppressionStore() *MemorySuppressionStore {
+	return &MemorySuppressionStore{suppressions: map[string]map[string]*Suppression{}}
+}
+
+func (m *MemorySuppressionStore) Add(suppression *Suppression) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.add(suppression)
+
+	return nil
+}
+
+func (m *MemorySuppressionStore) Remove(to *PhoneNumber, category string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(to, category)
+
+	return nil
+}
+
+func (m *MemorySuppressionStore) Check(to *PhoneNumber, category string) (*Suppression, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	categories := m.suppressions[suppressionKey(to)]
+
+	if suppression, ok := categories[""]; ok {
+		return suppression, true, nil
+	}
+
+	if suppression, ok := categories[category]; ok && category != "" {
+		return suppression, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (m *MemorySuppressionStore) List() ([]*Suppression, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var suppressions []*Suppression
+	for _, categories := range m.suppressions {
+		for _, suppression := range categories {
+			suppressions = append(suppressions, suppression)
+		}
+	}
+
+	sort.Slice(suppressions, func(i, j int) bool {
+		ki, kj := suppressionKey(suppressions[i].To), suppressionKey(suppressions[j].To)
+		if ki != kj {
+			return ki < kj
+		}
+		return suppressions[i].Category < suppressions[j].Category
+	})
+
+	return suppressions, nil
+}
+
+// Len Number of suppressions.
+func (m *MemorySuppressionStore) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n := 0
+	for _, categories := range m.suppressions {
+		n += len(categories)
+	}
+
+	return n
+}
+
+// add Add the suppression, callers hold the lock.
+func (m *MemorySuppressionStore) add(suppression *Suppression) {
+	if suppression.CreatedAt.IsZero() {
+		copied := *suppression
+		copied.CreatedAt = time.Now()
+		suppression = &copied
+	}
+
+	key := suppressionKey(suppression.To)
+
+	categories, ok := m.suppressions[key]
+	if !ok {
+		categories = map[string]*Suppression{}
+		m.suppressions[key] = categories
+	}
+
+	categories[suppression.Category] = suppression
+}
+
+// remove Remove the suppression, callers hold the lock.
+func (m *MemorySuppressionStore) remove(to *PhoneNumber, category string) {
+	key := suppressionKey(to)
+
+	categories, ok := m.suppressions[key]
+	if !ok {
+		return
+	}
+
+	delete(categories, category)
+
+	if len(categories) == 0 {
+		delete(m.suppressions, key)
+	}
+}
+
+// ImportSuppressionsCSV Add the suppressions of the CSV to the store and return how many were added.
+// The columns are number, idd_code, category, reason and created_at, only number is required and the header is
+// optional.
+func ImportSuppressionsCSV(r io.Reader, store SuppressionStore) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	n := 0
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+
+		if err != nil {
+			return n, err
+		}
+
+		if line == 1 && strings.EqualFold(record[0], suppressionHeader[0]) {
+			continue
+		}
+
+		suppression, err := parseSuppression(record)
+		if err != nil {
+			return n, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := store.Add(suppression); err != nil {
+			return n, err
+		}
+
+		n++
+	}
+}
+
+// ExportSuppressionsCSV Write the suppressions of the store as CSV with a header.
+func ExportSuppressionsCSV(w io.Writer, store SuppressionStore) error {
+	suppressions, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(suppressionHeader); err != nil {
+		return err
+	}
+
+	for _, suppression := range suppressions {
+		if err := writer.Write(formatSuppression(suppression)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// formatSuppression Format the suppression as a CSV record.
+func formatSuppression(suppression *Suppression) []string {
+	iddCode := ""
+	if suppression.To.IDDCode() != 0 {
+		iddCode = strconv.Itoa(suppression.To.IDDCode())
+	}
+
+	createdAt := ""
+	if !suppression.CreatedAt.IsZero() {
+		createdAt = suppression.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return []string{strconv.Itoa(suppression.To.Number()), iddCode, suppression.Category, suppression.Reason, createdAt}
+}
+
+// parseSuppression Parse a CSV record into a suppression.
+func parseSuppression(record []string) (*Suppression, error) {
+	field := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	number, err := strconv.Atoi(field(0))
+	if err != nil {
+		return nil, ErrInvalidPhoneNumber
+	}
+
+	suppression := &Suppression{
+		To:       NewPhoneNumber(number, field(1)),
+		Category: field(2),
+		Reason:   field(3),
+	}
+
+	if createdAt := field(4); createdAt != "" {
+		if suppression.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return suppression, nil
+}
@@ -0,0 +1,201 @@
+package gsms
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// suppressionOpAdd Journal operation adding a suppression.
+	suppressionOpAdd = "add"
+	// suppressionOpRemove Journal operation removing a suppression.
+	suppressionOpRemove = "remove"
+)
+
+var _ SuppressionStore = (*FileSuppressionStore)(nil)
+
+// FileSuppressionStore Suppression list kept in memory and journaled to a CSV file.
+// Every change is appended and synced before it is applied, the journal is compacted when the store is opened.
+// A file must be used by one process at a time.
+type FileSuppressionStore struct {
+	path string
+
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+	memory *MemorySuppressionStore
+}
+
+// NewFileSuppressionStore Open the suppression list of the file, it is created if it does not exist.
+func NewFileSuppressionStore(path string) (*FileSuppressionStore, error) {
+	f := &FileSuppressionStore{
+		path:   path,
+		memory: NewMemorySuppressionStore(),
+	}
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	if err := f.compact(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	f.file = file
+	f.writer = csv.NewWriter(file)
+
+	return f, nil
+}
+
+func (f *FileSuppressionStore) Add(suppression *Suppression) error {
+	if suppression.CreatedAt.IsZero() {
+		copied := *suppression
+		copied.CreatedAt = time.Now()
+		suppression = &copied
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.append(append([]string{suppressionOpAdd}, formatSuppression(suppression)...)); err != nil {
+		return err
+	}
+
+	return f.memory.Add(suppression)
+}
+
+func (f *FileSuppressionStore) Remove(to *PhoneNumber, category string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.append(append([]string{suppressionOpRemove}, formatSuppression(&Suppression{To: to, Category: category})...)); err != nil {
+		return err
+	}
+
+	return f.memory.Remove(to, category)
+}
+
+func (f *FileSuppressionStore) Check(to *PhoneNumber, category string) (*Suppression, bool, error) {
+	return f.memory.Check(to, category)
+}
+
+func (f *FileSuppressionStore) List() ([]*Suppression, error) {
+	return f.memory.List()
+}
+
+// Len Number of suppressions.
+func (f *FileSuppressionStore) Len() int {
+	return f.memory.Len()
+}
+
+// Close Close the journal.
+func (f *FileSuppressionStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+// append Append the record to the journal and sync it.
+func (f *FileSuppressionStore) append(record []string) error {
+	if err := f.writer.Write(record); err != nil {
+		return err
+	}
+
+	f.writer.Flush()
+	if err := f.writer.Error(); err != nil {
+		return err
+	}
+
+	return f.file.Sync()
+}
+
+// load Replay the journal.
+func (f *FileSuppressionStore) load() error {
+	file, err := os.Open(f.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(record) < 2 {
+			continue
+		}
+
+		suppression, err := parseSuppression(record[1:])
+		if err != nil {
+			return err
+		}
+
+		switch record[0] {
+		case suppressionOpAdd:
+			f.memory.add(suppression)
+		case suppressionOpRemove:
+			f.memory.remove(suppression.To, suppression.Category)
+		}
+	}
+}
+
+// compact Replace the journal with the current suppressions.
+func (f *FileSuppressionStore) compact() error {
+	suppressions, _ := f.memory.List()
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	writer := csv.NewWriter(tmp)
+	for _, suppression := range suppressions {
+		if err := writer.Write(append([]string{suppressionOpAdd}, formatSuppression(suppression)...)); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,136 @@
+package gsms
+
+import (
+	"bytes"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testSuppressionStore(t *testing.T, store SuppressionStore) {
+	assert.NoError(t, store.Add(&Suppression{To: NewPhoneNumberWithoutIDDCode(18888888888), Reason: "STOP"}))
+	assert.NoError(t, store.Add(&Suppression{To: NewPhoneNumber(18888888889, "86"), Category: CategoryMarketing}))
+
+	// Numbers without IDD code are the same as +86 numbers.
+	suppression, ok, err := store.Check(NewPhoneNumber(18888888888, "86"), CategoryOTP)
+	assert.NoError(t, err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "STOP", suppression.Reason)
+		assert.False(t, suppression.CreatedAt.IsZero())
+	}
+
+	_, ok, _ = store.Check(NewPhoneNumberWithoutIDDCode(18888888889), CategoryMarketing)
+	assert.True(t, ok)
+
+	_, ok, _ = store.Check(NewPhoneNumberWithoutIDDCode(18888888889), CategoryNotification)
+	assert.False(t, ok)
+
+	assert.NoError(t, store.Remove(NewPhoneNumberWithoutIDDCode(18888888889), CategoryMarketing))
+
+	_, ok, _ = store.Check(NewPhoneNumberWithoutIDDCode(18888888889), CategoryMarketing)
+	assert.False(t, ok)
+
+	suppressions, err := store.List()
+	assert.NoError(t, err)
+	assert.Len(t, suppressions, 1)
+}
+
+func TestMemorySuppressionStore(t *testing.T) {
+	testSuppressionStore(t, NewMemorySuppressionStore())
+}
+
+func TestFileSuppressionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.csv")
+
+	store, err := NewFileSuppressionStore(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	testSuppressionStore(t, store)
+	assert.NoError(t, store.Close())
+
+	reopened, err := NewFileSuppressionStore(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reopened.Close()
+
+	assert.Equal(t, 1, reopened.Len())
+
+	suppression, ok, _ := reopened.Check(NewPhoneNumberWithoutIDDCode(18888888888), CategoryMarketing)
+	if assert.True(t, ok) {
+		assert.Equal(t, "STOP", suppression.Reason)
+	}
+
+	_, ok, _ = reopened.Check(NewPhoneNumberWithoutIDDCode(18888888889), CategoryMarketing)
+	assert.False(t, ok)
+}
+
+func TestSuppressionsCSV(t *testing.T) {
+	store := NewMemorySuppressionStore()
+
+	n, err := ImportSuppressionsCSV(strings.NewReader(`number,idd_code,category,reason,created_at
+18888888888,,,STOP,2023-06-01T08:00:00Z
+9012345678,81,marketing,"complaint, blocked",
+`), store)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, ok, _ := store.Check(NewPhoneNumber(9012345678, "81"), CategoryMarketing)
+	assert.True(t, ok)
+
+	var buf bytes.Buffer
+	assert.NoError(t, ExportSuppressionsCSV(&buf, store))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Equal(t, "number,idd_code,category,reason,created_at", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], `9012345678,81,marketing,"complaint, blocked",`))
+		assert.Equal(t, "18888888888,,,STOP,2023-06-01T08:00:00Z", lines[2])
+	}
+
+	imported := NewMemorySuppressionStore()
+	n, err = ImportSuppressionsCSV(&buf, imported)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = ImportSuppressionsCSV(strings.NewReader("abc\n"), imported)
+	assert.ErrorIs(t, err, ErrInvalidPhoneNumber)
+}
+
+func TestGsms_Send_Suppressed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := NewMemorySuppressionStore()
+	assert.NoError(t, store.Add(&Suppression{To: NewPhoneNumberWithoutIDDCode(18888888888), Category: CategoryMarketing, Reason: "STOP"}))
+
+	gateway := &countGateway{}
+	g := New([]Gateway{gateway}, WithGateways([]string{"count"}), WithSuppressionStore(store))
+
+	_, err := g.Send(18888888888, newCategorizedMessage(ctrl, CategoryMarketing))
+
+	var suppressed *ErrRecipientSuppressed
+	if assert.True(t, errors.As(err, &suppressed)) {
+		assert.Equal(t, CategoryMarketing, suppressed.Category)
+		assert.Equal(t, "STOP", suppressed.Suppression.Reason)
+	}
+
+	_, err = g.Send(18888888888, newCategorizedMessage(ctrl, CategoryNotification))
+	assert.NoError(t, err)
+
+	results, err := g.SendBatch([]*PhoneNumber{
+		NewPhoneNumberWithoutIDDCode(18888888888),
+		NewPhoneNumberWithoutIDDCode(18888888889),
+	}, newCategorizedMessage(ctrl, CategoryMarketing))
+
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSuppressed, results[0].Status)
+	assert.Equal(t, StatusSuccess, results[1].Status)
+
+	assert.Equal(t, int32(2), gateway.calls)
+}
@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Option func(*Tracer)
+
+// WithTracerProvider set the tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) func(*Tracer) {
+	return func(t *Tracer) {
+		t.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithPropagation inject the trace context into the requests to the providers with the global propagator.
+// The trace IDs are sent to third parties, so it is disabled by default.
+func WithPropagation() func(*Tracer) {
+	return func(t *Tracer) {
+		t.propagator = otel.GetTextMapPropagator()
+	}
+}
+
+// WithPropagator inject the trace context into the requests to the providers with the propagator, nil disables it.
+func WithPropagator(propagator propagation.TextMapPropagator) func(*Tracer) {
+	return func(t *Tracer) {
+		t.propagator = propagator
+	}
+}
@@ -0,0 +1,208 @@
+// Package tracing OpenTelemetry tracing of gsms.
+// It is a separate module so that gsms does not depend on OpenTelemetry.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/maiqingqiang/gsms"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/url"
+)
+
+// instrumentationName Name of the tracer.
+const instrumentationName = "github.com/maiqingqiang/gsms/tracing"
+
+const (
+	// SendSpanName Name of the span of a send.
+	SendSpanName = "gsms.Send"
+	// AttemptSpanName Name of the span of a gateway attempt.
+	AttemptSpanName = "gsms.Attempt"
+	// RequestSpanName Name of the span of an HTTP request to a provider.
+	RequestSpanName = "gsms.Request"
+)
+
+const (
+	// RecipientKey Masked phone number of the recipient.
+	RecipientKey = attribute.Key("gsms.recipient")
+	// CategoryKey Category of the message.
+	CategoryKey = attribute.Key("gsms.category")
+	// GatewaysKey Requested gateways of the send.
+	GatewaysKey = attribute.Key("gsms.gateways")
+	// AttemptsKey Number of attempts of the send.
+	AttemptsKey = attribute.Key("gsms.attempts")
+	// GatewayKey Gateway of the attempt or the request.
+	GatewayKey = attribute.Key("gsms.gateway")
+	// TemplateKey Template of the attempt or the request.
+	TemplateKey = attribute.Key("gsms.template")
+	// MessageIDKey Provider message ID of a successful attempt.
+	MessageIDKey = attribute.Key("gsms.message_id")
+	// ErrorClassKey Error class of a failed span, see gsms.ClassifyError.
+	ErrorClassKey = attribute.Key("gsms.error_class")
+	// MethodKey HTTP method of the request.
+	MethodKey = attribute.Key("http.method")
+	// URLKey HTTP URL of the request without the query, which may contain credentials.
+	URLKey = attribute.Key("http.url")
+	// StatusCodeKey HTTP status code of the response.
+	StatusCodeKey = attribute.Key("http.status_code")
+)
+
+// attemptContextKey Context key of the attempt an HTTP request belongs to.
+type attemptContextKey struct{}
+
+// attempt Gateway attempt an HTTP request belongs to.
+type attempt struct {
+	gateway  string
+	template string
+}
+
+// Tracer OpenTelemetry tracer of gsms, it traces the sends, their gateway attempts and the HTTP requests to the
+// providers. Phone numbers are masked, the trace context is only injected into the requests with WithPropagation.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New New a tracer, the global tracer provider is used by default.
+func New(options ...Option) *Tracer {
+	t := &Tracer{
+		tracer: otel.GetTracerProvider().Tracer(instrumentationName),
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// Instrument Option tracing the gsms instance, it sets the request hook of the gateways.
+// Batch sends are not traced, their attempts are.
+func (t *Tracer) Instrument() func(*gsms.Gsms) {
+	return func(g *gsms.Gsms) {
+		gsms.WithSendInterceptor(t.traceSend)(g)
+		gsms.WithMiddleware(t.traceAttempt)(g)
+		gsms.WithRequestHook(t.traceRequest)(g)
+	}
+}
+
+// traceSend Start the span of a send, the caller's span is the parent.
+func (t *Tracer) traceSend(ctx context.Context, request *gsms.SendRequest, send func(ctx context.Context) ([]*gsms.Result, error)) ([]*gsms.Result, error) {
+	attributes := []attribute.KeyValue{RecipientKey.String(request.To.Masked())}
+
+	if len(request.Gateways) > 0 {
+		attributes = append(attributes, GatewaysKey.StringSlice(request.Gateways))
+	}
+
+	if categorized, ok := request.Message.(gsms.CategorizedMessage); ok && categorized.GetCategory() != "" {
+		attributes = append(attributes, CategoryKey.String(categorized.GetCategory()))
+	}
+
+	ctx, span := t.tracer.Start(ctx, SendSpanName, trace.WithAttributes(attributes...))
+	defer span.End()
+
+	results, err := send(ctx)
+
+	span.SetAttributes(AttemptsKey.Int(attempts(results, err)))
+	setError(span, err)
+
+	return results, err
+}
+
+// attempts Number of attempts of a send, the results of a failed send are in its error.
+func attempts(results []*gsms.Result, err error) int {
+	var failed *gsms.ErrGatewaysFailed
+	if results == nil && errors.As(err, &failed) {
+		return len(failed.Results)
+	}
+
+	return len(results)
+}
+
+// traceAttempt Middleware starting the span of a gateway attempt.
+func (t *Tracer) traceAttempt(next gsms.SendFunc) gsms.SendFunc {
+	return func(ctx context.Context, gateway gsms.Gateway, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) (*gsms.Receipt, error) {
+		a := &attempt{gateway: gateway.Name()}
+
+		if template, err := message.GetTemplate(gateway); err == nil {
+			a.template = template
+		}
+
+		ctx, span := t.tracer.Start(context.WithValue(ctx, attemptContextKey{}, a), AttemptSpanName, trace.WithAttributes(
+			GatewayKey.String(a.gateway),
+			TemplateKey.String(a.template),
+			RecipientKey.String(to.Masked()),
+		))
+		defer span.End()
+
+		receipt, err := next(ctx, gateway, to, message, config)
+
+		if receipt != nil && receipt.MessageID != "" {
+			span.SetAttributes(MessageIDKey.String(receipt.MessageID))
+		}
+
+		setError(span, err)
+
+		return receipt, err
+	}
+}
+
+// traceRequest Request hook starting the span of an HTTP request, the trace context is injected into the headers
+// if propagation is enabled.
+func (t *Tracer) traceRequest(req *http.Request) (*http.Request, func(statusCode int, err error)) {
+	attributes := []attribute.KeyValue{
+		MethodKey.String(req.Method),
+		URLKey.String(redact(req.URL)),
+	}
+
+	if a, ok := req.Context().Value(attemptContextKey{}).(*attempt); ok {
+		attributes = append(attributes, GatewayKey.String(a.gateway), TemplateKey.String(a.template))
+	}
+
+	ctx, span := t.tracer.Start(req.Context(), RequestSpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+
+	req = req.Clone(ctx)
+
+	if t.propagator != nil {
+		t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	return req, func(statusCode int, err error) {
+		if statusCode > 0 {
+			span.SetAttributes(StatusCodeKey.Int(statusCode))
+		}
+
+		setError(span, err)
+		span.End()
+	}
+}
+
+// setError Record the error on the span with its class.
+func setError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.SetAttributes(ErrorClassKey.String(gsms.ClassifyError(err).String()))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// redact URL without the credentials and the query.
+func redact(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+	redacted.ForceQuery = false
+	redacted.Fragment = ""
+
+	return redacted.String()
+}
@@ -0,0 +1,243 @@
+package tracing
+
+import (
+	"context"
+	"github.com/maiqingqiang/gsms"
+	"github.com/maiqingqiang/gsms/message"
+	"github.com/maiqingqiang/gsms/utils/dove"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testGateway struct {
+	name string
+	url  string
+}
+
+func (t *testGateway) Name() string {
+	return t.name
+}
+
+func (t *testGateway) Send(to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	return t.SendContext(context.Background(), to, message, config)
+}
+
+func (t *testGateway) SendContext(ctx context.Context, to *gsms.PhoneNumber, message gsms.Message, config *gsms.Config) error {
+	d := dove.New(dove.WithLogger(config.Logger), dove.WithRequestHook(config.RequestHook))
+
+	var response map[string]string
+	return d.PostContext(ctx, t.url+"?signature=secret", strings.NewReader("{}"), &response)
+}
+
+// attributes Attributes of the span by key.
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	values := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		values[kv.Key] = kv.Value
+	}
+
+	return values
+}
+
+func TestTracer(t *testing.T) {
+	var traceparents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+
+		if r.URL.Path == "/a" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"code":"OK"}`))
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	tracer := New(WithTracerProvider(provider), WithPropagator(propagation.TraceContext{}))
+
+	client := gsms.New([]gsms.Gateway{
+		&testGateway{name: "a", url: server.URL + "/a"},
+		&testGateway{name: "b", url: server.URL + "/b"},
+	},
+		gsms.WithGateways([]string{"a", "b"}),
+		tracer.Instrument(),
+	)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	_, err := client.SendContext(ctx, 18888888888, &message.Message{
+		Template: "SMS_001",
+		Category: gsms.CategoryNotification,
+	})
+	parent.End()
+
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 6) {
+		return
+	}
+
+	byID := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		byID[span.SpanContext.SpanID().String()] = span
+	}
+
+	parentOf := func(span tracetest.SpanStub) tracetest.SpanStub {
+		return byID[span.Parent.SpanID().String()]
+	}
+
+	requestA, attemptA, requestB, attemptB, send := spans[0], spans[1], spans[2], spans[3], spans[4]
+
+	assert.Equal(t, SendSpanName, send.Name)
+	assert.Equal(t, "parent", parentOf(send).Name)
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		RecipientKey: attribute.StringValue("188****8888"),
+		CategoryKey:  attribute.StringValue(gsms.CategoryNotification),
+		AttemptsKey:  attribute.IntValue(2),
+	}, attributes(send))
+	assert.Equal(t, codes.Unset, send.Status.Code)
+
+	for _, attempt := range []tracetest.SpanStub{attemptA, attemptB} {
+		assert.Equal(t, AttemptSpanName, attempt.Name)
+		assert.Equal(t, send.SpanContext.SpanID(), attempt.Parent.SpanID())
+	}
+
+	assert.Equal(t, attribute.StringValue("a"), attributes(attemptA)[GatewayKey])
+	assert.Equal(t, attribute.StringValue("SMS_001"), attributes(attemptA)[TemplateKey])
+	assert.Equal(t, attribute.StringValue("188****8888"), attributes(attemptA)[RecipientKey])
+	assert.Equal(t, attribute.StringValue("transient"), attributes(attemptA)[ErrorClassKey])
+	assert.Equal(t, codes.Error, attemptA.Status.Code)
+	assert.Equal(t, attribute.StringValue("b"), attributes(attemptB)[GatewayKey])
+	assert.Equal(t, codes.Unset, attemptB.Status.Code)
+
+	for _, request := range []tracetest.SpanStub{requestA, requestB} {
+		assert.Equal(t, RequestSpanName, request.Name)
+	}
+
+	assert.Equal(t, attemptA.SpanContext.SpanID(), requestA.Parent.SpanID())
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		MethodKey:     attribute.StringValue(http.MethodPost),
+		URLKey:        attribute.StringValue(server.URL + "/a"),
+		GatewayKey:    attribute.StringValue("a"),
+		TemplateKey:   attribute.StringValue("SMS_001"),
+		StatusCodeKey: attribute.IntValue(http.StatusServiceUnavailable),
+		ErrorClassKey: attribute.StringValue("transient"),
+	}, attributes(requestA))
+	assert.Equal(t, codes.Error, requestA.Status.Code)
+
+	assert.Equal(t, attemptB.SpanContext.SpanID(), requestB.Parent.SpanID())
+	assert.Equal(t, attribute.IntValue(http.StatusOK), attributes(requestB)[StatusCodeKey])
+	assert.Equal(t, codes.Unset, requestB.Status.Code)
+
+	if assert.Len(t, traceparents, 2) {
+		assert.Contains(t, traceparents[0], requestA.SpanContext.SpanID().String())
+		assert.Contains(t, traceparents[1], requestB.SpanContext.SpanID().String())
+	}
+}
+
+func TestTracer_Disabled(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("traceparent"))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := gsms.New([]gsms.Gateway{&testGateway{name: "a", url: server.URL}}, gsms.WithGateways([]string{"a"}))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+
+	_, err := client.SendContext(ctx, 18888888888, &message.Message{Template: "SMS_001"})
+	parent.End()
+
+	assert.NoError(t, err)
+	assert.Len(t, exporter.GetSpans(), 1)
+}
+
+func TestTracer_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	client := gsms.New([]gsms.Gateway{
+		&testGateway{name: "a", url: server.URL},
+		&testGateway{name: "b", url: server.URL},
+	},
+		gsms.WithGateways([]string{"a", "b"}),
+		New(WithTracerProvider(provider)).Instrument(),
+	)
+
+	_, err := client.Send(18888888888, &message.Message{Template: "SMS_001"})
+	if !assert.Error(t, err) {
+		return
+	}
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 5) {
+		return
+	}
+
+	send := spans[4]
+	assert.Equal(t, SendSpanName, send.Name)
+	assert.Equal(t, attribute.IntValue(2), attributes(send)[AttemptsKey])
+	assert.Equal(t, codes.Error, send.Status.Code)
+}
+
+func TestTracer_NoPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	var traceparent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	for _, propagation := range []bool{false, true} {
+		options := []Option{WithTracerProvider(provider)}
+		if propagation {
+			options = append(options, WithPropagation())
+		}
+
+		client := gsms.New([]gsms.Gateway{&testGateway{name: "a", url: server.URL}},
+			gsms.WithGateways([]string{"a"}),
+			New(options...).Instrument(),
+		)
+
+		_, err := client.Send(18888888888, &message.Message{Template: "SMS_001"})
+		assert.NoError(t, err)
+
+		// The spans are recorded either way, the trace context is only sent to the provider with propagation.
+		assert.Equal(t, propagation, traceparent != "")
+		assert.NotEmpty(t, exporter.GetSpans())
+
+		exporter.Reset()
+	}
+}
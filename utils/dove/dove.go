@@ -1,6 +1,7 @@
 package dove
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/maiqingqiang/gsms"
@@ -16,11 +17,12 @@ type Dove struct {
 	statusCodeJudger StatusCodeJudger
 	unmarshal        Unmarshal
 	logger           gsms.Logger
+	requestHook      gsms.RequestHook
 }
 
 func New(opts ...Option) *Dove {
 	dove := &Dove{
-		client: http.DefaultClient,
+		client: &http.Client{},
 		statusCodeJudger: func(statusCode int) error {
 			if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
 				return fmt.Errorf("request status code: %d", statusCode)
@@ -39,7 +41,12 @@ func New(opts ...Option) *Dove {
 }
 
 func (d *Dove) Request(method, url string, header http.Header, data io.Reader, response interface{}) error {
-	req, err := http.NewRequest(method, url, data)
+	return d.RequestContext(context.Background(), method, url, header, data, response)
+}
+
+// RequestContext Send a request with context.
+func (d *Dove) RequestContext(ctx context.Context, method, url string, header http.Header, data io.Reader, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, data)
 	if err != nil {
 		return err
 	}
@@ -52,46 +59,76 @@ func (d *Dove) Request(method, url string, header http.Header, data io.Reader, r
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	}
 
+	if d.requestHook == nil {
+		_, err = d.do(req, response)
+		return err
+	}
+
+	req, done := d.requestHook(req)
+
+	statusCode, err := d.do(req, response)
+	done(statusCode, err)
+
+	return err
+}
+
+// do Send the request and unmarshal the response, it returns the status code, 0 if there is no response.
+func (d *Dove) do(req *http.Request, response interface{}) (int, error) {
 	d.logger.Infof("request url: %s , method: %s , header: %+v , reqBody: %s", req.URL, req.Method, req.Header, req.Body)
 
 	resp, err := d.client.Do(req)
 	if err != nil {
 		d.logger.Warnf("request failed: %v", err)
-		return err
+		return 0, err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		d.logger.Warnf("io.ReadAll failed: %v", err)
-		return err
+		return resp.StatusCode, err
 	}
 
 	d.logger.Infof("response status code: %d , body: %s", resp.StatusCode, body)
 
 	err = d.statusCodeJudger(resp.StatusCode)
 	if err != nil {
-		return err
-	}
-
-	err = d.unmarshal(body, response)
-	if err != nil {
-		return err
+		return resp.StatusCode, &gsms.ErrRequestFailed{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			Message:    err.Error(),
+		}
 	}
 
-	return nil
+	return resp.StatusCode, d.unmarshal(body, response)
 }
 
 func (d *Dove) Get(url string, data io.Reader, response interface{}) error {
-	return d.Request(http.MethodGet, url, nil, data, response)
+	return d.GetContext(context.Background(), url, data, response)
+}
+
+// GetContext Send a GET request with context.
+func (d *Dove) GetContext(ctx context.Context, url string, data io.Reader, response interface{}) error {
+	return d.RequestContext(ctx, http.MethodGet, url, nil, data, response)
 }
 
 func (d *Dove) Post(url string, data io.Reader, response interface{}) error {
-	return d.Request(http.MethodPost, url, nil, data, response)
+	return d.PostContext(context.Background(), url, data, response)
+}
+
+// PostContext Send a POST request with context.
+func (d *Dove) PostContext(ctx context.Context, url string, data io.Reader, response interface{}) error {
+	return d.RequestContext(ctx, http.MethodPost, url, nil, data, response)
 }
 
 func (d *Dove) PostForm(url string, data io.Reader, response interface{}) error {
+	return d.PostFormContext(context.Background(), url, data, response)
+}
+
+// PostFormContext Send a form POST request with context.
+func (d *Dove) PostFormContext(ctx context.Context, url string, data io.Reader, response interface{}) error {
 	header := http.Header{}
 	header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	return d.Request(http.MethodPost, url, header, data, response)
+	return d.RequestContext(ctx, http.MethodPost, url, header, data, response)
 }
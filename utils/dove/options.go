@@ -24,3 +24,10 @@ func WithUnmarshal(unmarshal Unmarshal) Option {
 		dove.unmarshal = unmarshal
 	}
 }
+
+// WithRequestHook set the hook of the requests, nil for none.
+func WithRequestHook(hook gsms.RequestHook) Option {
+	return func(dove *Dove) {
+		dove.requestHook = hook
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import "unicode/utf16"
+
+const (
+	gsm7SingleLength = 160
+	gsm7MultiLength  = 153
+	ucs2SingleLength = 70
+	ucs2MultiLength  = 67
+)
+
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+const gsm7Extension = "\f^{}\\[~]|€"
+
+var gsm7Septets = func() map[rune]int {
+	septets := make(map[rune]int, len(gsm7Basic)+len(gsm7Extension))
+	for _, r := range gsm7Basic {
+		septets[r] = 1
+	}
+	for _, r := range gsm7Extension {
+		septets[r] = 2
+	}
+	return septets
+}()
+
+// SegmentCount Count the SMS segments of the content.
+// Content in the GSM 7-bit alphabet takes 160 characters per segment (153 if concatenated),
+// other content is encoded in UCS-2 and takes 70 characters per segment (67 if concatenated).
+func SegmentCount(content string) int {
+	if content == "" {
+		return 0
+	}
+
+	septets := 0
+	for _, r := range content {
+		n, ok := gsm7Septets[r]
+		if !ok {
+			return segments(len(utf16.Encode([]rune(content))), ucs2SingleLength, ucs2MultiLength)
+		}
+		septets += n
+	}
+
+	return segments(septets, gsm7SingleLength, gsm7MultiLength)
+}
+
+// segments Number of segments for the length.
+func segments(length, single, multi int) int {
+	if length <= single {
+		return 1
+	}
+
+	return (length + multi - 1) / multi
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSegmentCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "empty", content: "", want: 0},
+		{name: "gsm7 single", content: strings.Repeat("a", 160), want: 1},
+		{name: "gsm7 multi", content: strings.Repeat("a", 161), want: 2},
+		{name: "gsm7 extension", content: strings.Repeat("€", 80) + "a", want: 2},
+		{name: "ucs2 single", content: "【Gsms】您的验证码是521410", want: 1},
+		{name: "ucs2 multi", content: strings.Repeat("验", 71), want: 2},
+		{name: "ucs2 multi exact", content: strings.Repeat("验", 134), want: 2},
+		{name: "ucs2 surrogate pair", content: strings.Repeat("😀", 35) + "a", want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SegmentCount(tt.content))
+		})
+	}
+}